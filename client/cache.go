@@ -0,0 +1,173 @@
+package client
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cached resources
+const (
+	ResourceAssistant = "assistant"
+	ResourceThread    = "thread"
+)
+
+// Cache is a size-bounded LRU cache of retrieve responses. Entries expire after the TTL
+// of their resource, and concurrent requests for the same entry share one API call.
+// Entries are kept per API key and base URL, so one cache can be shared by several clients.
+// It is safe for concurrent use.
+type Cache struct {
+	maxEntries int
+	ttls       map[string]time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	order    *list.List
+	entries  map[string]*list.Element
+	inflight map[string]*cacheCall
+}
+
+type cacheEntry struct {
+	key     string
+	ref     string
+	body    []byte
+	expires time.Time
+}
+
+type cacheCall struct {
+	ref         string
+	done        chan struct{}
+	body        []byte
+	err         error
+	invalidated bool
+}
+
+// NewCache creates a cache holding at most maxEntries responses. ttls sets how long each
+// resource, such as ResourceAssistant, is cached; resources without a TTL are not cached.
+func NewCache(maxEntries int, ttls map[string]time.Duration) *Cache {
+	copied := make(map[string]time.Duration, len(ttls))
+	for resource, ttl := range ttls {
+		copied[resource] = ttl
+	}
+	return &Cache{
+		maxEntries: maxEntries,
+		ttls:       copied,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		inflight:   make(map[string]*cacheCall),
+	}
+}
+
+// enabled reports whether responses for resource are cached
+func (c *Cache) enabled(resource string) bool {
+	return c != nil && c.maxEntries > 0 && c.ttls[resource] > 0
+}
+
+// load returns the cached body for the entry of scope or calls fetch, sharing the call
+// with concurrent loads of the same entry
+func (c *Cache) load(scope, resource, key string, fetch func() ([]byte, error)) ([]byte, error) {
+	ref := resource + "/" + key
+	id := scope + " " + ref
+
+	c.mu.Lock()
+	if el, ok := c.entries[id]; ok {
+		entry := el.Value.(*cacheEntry)
+		if c.now().Before(entry.expires) {
+			c.order.MoveToFront(el)
+			c.mu.Unlock()
+			return entry.body, nil
+		}
+		c.remove(el)
+	}
+	if call, ok := c.inflight[id]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.body, call.err
+	}
+	call := &cacheCall{ref: ref, done: make(chan struct{})}
+	c.inflight[id] = call
+	c.mu.Unlock()
+
+	call.body, call.err = fetch()
+
+	c.mu.Lock()
+	if c.inflight[id] == call {
+		delete(c.inflight, id)
+	}
+	// A response fetched while the entry was invalidated may be stale, so it is returned but not stored
+	if call.err == nil && !call.invalidated {
+		c.store(id, ref, call.body, c.now().Add(c.ttls[resource]))
+	}
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.body, call.err
+}
+
+// store adds an entry and evicts the least recently used entries over the limit; the caller must hold c.mu
+func (c *Cache) store(id, ref string, body []byte, expires time.Time) {
+	if el, ok := c.entries[id]; ok {
+		c.remove(el)
+	}
+	c.entries[id] = c.order.PushFront(&cacheEntry{key: id, ref: ref, body: body, expires: expires})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove deletes an entry; the caller must hold c.mu
+func (c *Cache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
+}
+
+// Invalidate removes a cached resource for every client sharing the cache.
+// A request for it that is in flight is not stored.
+func (c *Cache) Invalidate(resource, key string) {
+	if c == nil {
+		return
+	}
+	ref := resource + "/" + key
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, el := range c.entries {
+		if el.Value.(*cacheEntry).ref == ref {
+			c.remove(el)
+		}
+	}
+	for id, call := range c.inflight {
+		if call.ref == ref {
+			call.invalidated = true
+			delete(c.inflight, id)
+		}
+	}
+}
+
+// Purge removes every cached response
+func (c *Cache) Purge() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	for id, call := range c.inflight {
+		call.invalidated = true
+		delete(c.inflight, id)
+	}
+}
+
+// Len returns the number of cached responses
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
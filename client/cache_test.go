@@ -0,0 +1,172 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheTTLAndLRU(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewCache(2, map[string]time.Duration{ResourceAssistant: time.Minute})
+	cache.now = func() time.Time { return now }
+
+	fetches := 0
+	fetch := func(key string) []byte {
+		body, err := cache.load("scope", ResourceAssistant, key, func() ([]byte, error) {
+			fetches++
+			return []byte(fmt.Sprintf("%s-%d", key, fetches)), nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return body
+	}
+
+	fetch("a")
+	if got := string(fetch("a")); got != "a-1" {
+		t.Errorf("Expected cached body a-1, got %s", got)
+	}
+
+	fetch("b")
+	fetch("a") // a is now the most recently used entry
+	fetch("c") // evicts b
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", cache.Len())
+	}
+	if got := string(fetch("b")); got != "b-4" {
+		t.Errorf("Expected b to be refetched after eviction, got %s", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if got := string(fetch("b")); got != "b-5" {
+		t.Errorf("Expected b to be refetched after expiry, got %s", got)
+	}
+
+	cache.Invalidate(ResourceAssistant, "b")
+	if got := string(fetch("b")); got != "b-6" {
+		t.Errorf("Expected b to be refetched after invalidation, got %s", got)
+	}
+
+	if cache.enabled(ResourceThread) {
+		t.Error("Expected resources without a TTL not to be cached")
+	}
+	var nilCache *Cache
+	if nilCache.enabled(ResourceAssistant) {
+		t.Error("Expected a nil cache to be disabled")
+	}
+	nilCache.Invalidate(ResourceAssistant, "a")
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write([]byte(`{"message":"hello"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+		Cache:      NewCache(10, map[string]time.Duration{ResourceAssistant: time.Minute}),
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", server.URL+"/assistants/asst_123", nil)
+			var resp struct {
+				Message string `json:"message"`
+			}
+			if err := client.SendCachedRequest(req, ResourceAssistant, "asst_123", &resp); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			results[i] = resp.Message
+		}(i)
+	}
+
+	// Give the goroutines time to join the in-flight request
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected 1 API call, got %d", n)
+	}
+	for _, result := range results {
+		if result != "hello" {
+			t.Errorf("Expected hello, got %q", result)
+		}
+	}
+}
+
+func TestCacheInvalidateInFlight(t *testing.T) {
+	cache := NewCache(10, map[string]time.Duration{ResourceThread: time.Minute})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.load("scope", ResourceThread, "thread_123", func() ([]byte, error) {
+			close(started)
+			<-release
+			return []byte("stale"), nil
+		})
+	}()
+
+	<-started
+	cache.Invalidate(ResourceThread, "thread_123")
+	close(release)
+	<-done
+
+	if cache.Len() != 0 {
+		t.Errorf("Expected the invalidated response not to be stored, got %d entries", cache.Len())
+	}
+}
+
+func TestCacheScopedByClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"message":%q}`, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	cache := NewCache(10, map[string]time.Duration{ResourceAssistant: time.Minute})
+	get := func(apiKey string) string {
+		c := &Client{BaseURL: server.URL, APIKey: apiKey, HTTPClient: server.Client(), Cache: cache}
+		req, _ := http.NewRequest("GET", server.URL+"/assistants/asst_123", nil)
+		var resp struct {
+			Message string `json:"message"`
+		}
+		if err := c.SendCachedRequest(req, ResourceAssistant, "asst_123", &resp); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return resp.Message
+	}
+
+	if got := get("key-a"); got != "Bearer key-a" {
+		t.Errorf("Expected response for key-a, got %q", got)
+	}
+	if got := get("key-b"); got != "Bearer key-b" {
+		t.Errorf("Expected a separate entry for key-b, got %q", got)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", cache.Len())
+	}
+
+	cache.Invalidate(ResourceAssistant, "asst_123")
+	if cache.Len() != 0 {
+		t.Errorf("Expected invalidation to remove the entry of every client, got %d entries", cache.Len())
+	}
+}
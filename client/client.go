@@ -1,6 +1,8 @@
 package client
 
 import (
+        "crypto/sha256"
+        "encoding/hex"
         "encoding/json"
         "fmt"
         "io"
@@ -16,6 +18,55 @@ type Client struct {
         BaseURL    string
         APIKey     string
         HTTPClient *http.Client
+
+        // RunTracker records runs created through this client when set
+        RunTracker *RunTracker
+
+        // ValidateRequests checks requests with their Validate method before sending them
+        ValidateRequests bool
+
+        // Cache caches retrieve calls when set
+        Cache *Cache
+
+        // Models checks requests against the capabilities of their model when set
+        Models ModelPolicy
+}
+
+// Validator is implemented by request types that can check themselves before being sent
+type Validator interface {
+        Validate() error
+}
+
+// Validate runs v.Validate when ValidateRequests is enabled
+func (c *Client) Validate(v Validator) error {
+        if !c.ValidateRequests || v == nil {
+                return nil
+        }
+        return v.Validate()
+}
+
+// ModelPolicy checks a request against the capabilities of the model it targets,
+// clearing or rejecting parameters the model does not support. assistantID names
+// the assistant whose model the request uses when it does not set one. Check returns
+// the request to send, leaving req itself unchanged.
+type ModelPolicy interface {
+        Check(req interface{}, assistantID string) (interface{}, error)
+}
+
+// CheckModel runs the client's model policy on req, if one is set, and returns the request to send
+func CheckModel[T any](c *Client, req T, assistantID string) (T, error) {
+        if c.Models == nil {
+                return req, nil
+        }
+        checked, err := c.Models.Check(req, assistantID)
+        if err != nil {
+                return req, err
+        }
+        out, ok := checked.(T)
+        if !ok {
+                return req, fmt.Errorf("model policy returned %T for a %T request", checked, req)
+        }
+        return out, nil
 }
 
 // APIError represents an error response from the OpenAI API
@@ -43,6 +94,56 @@ func NewClient(apiKey string) *Client {
 
 // SendRequest sends an HTTP request and decodes the response into v
 func (c *Client) SendRequest(req *http.Request, v interface{}) error {
+        body, err := c.send(req)
+        if err != nil {
+                return err
+        }
+
+        // Decode response
+        if err := json.Unmarshal(body, v); err != nil {
+                return fmt.Errorf("failed to decode response: %w", err)
+        }
+
+        return nil
+}
+
+// SendCachedRequest sends a retrieve request through the client's cache and decodes the
+// response into v. The response is cached under resource and key; without a cache, or
+// without a TTL for resource, it behaves like SendRequest.
+func (c *Client) SendCachedRequest(req *http.Request, resource, key string, v interface{}) error {
+        if !c.Cache.enabled(resource) {
+                return c.SendRequest(req, v)
+        }
+
+        body, err := c.Cache.load(c.cacheScope(), resource, key, func() ([]byte, error) {
+                return c.send(req)
+        })
+        if err != nil {
+                return err
+        }
+
+        // Decode response
+        if err := json.Unmarshal(body, v); err != nil {
+                return fmt.Errorf("failed to decode response: %w", err)
+        }
+
+        return nil
+}
+
+// Invalidate removes a cached resource, if the client has a cache
+func (c *Client) Invalidate(resource, key string) {
+        c.Cache.Invalidate(resource, key)
+}
+
+// cacheScope identifies the API key and base URL of cached responses, so clients sharing
+// a cache never see responses fetched with other credentials
+func (c *Client) cacheScope() string {
+        sum := sha256.Sum256([]byte(c.BaseURL + "\x00" + c.APIKey))
+        return hex.EncodeToString(sum[:16])
+}
+
+// send sends an HTTP request and returns the body of a successful response
+func (c *Client) send(req *http.Request) ([]byte, error) {
         // Set common headers
         req.Header.Set("Authorization", "Bearer "+c.APIKey)
         req.Header.Set("Content-Type", "application/json")
@@ -50,29 +151,24 @@ func (c *Client) SendRequest(req *http.Request, v interface{}) error {
         // Send request
         resp, err := c.HTTPClient.Do(req)
         if err != nil {
-                return fmt.Errorf("failed to send request: %w", err)
+                return nil, fmt.Errorf("failed to send request: %w", err)
         }
         defer resp.Body.Close()
 
         // Read response body
         body, err := io.ReadAll(resp.Body)
         if err != nil {
-                return fmt.Errorf("failed to read response body: %w", err)
+                return nil, fmt.Errorf("failed to read response body: %w", err)
         }
 
         // Check for error response
         if resp.StatusCode != http.StatusOK {
                 var apiErr APIError
                 if err := json.Unmarshal(body, &apiErr); err != nil {
-                        return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, body)
+                        return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, body)
                 }
-                return &apiErr
+                return nil, &apiErr
         }
 
-        // Decode response
-        if err := json.Unmarshal(body, v); err != nil {
-                return fmt.Errorf("failed to decode response: %w", err)
-        }
-
-        return nil
-}
\ No newline at end of file
+        return body, nil
+}
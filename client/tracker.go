@@ -0,0 +1,49 @@
+package client
+
+import "sync"
+
+// RunRef identifies a run on a thread
+type RunRef struct {
+	ThreadID string
+	RunID    string
+}
+
+// RunTracker records the runs created through a client that have not finished yet
+type RunTracker struct {
+	mu   sync.Mutex
+	runs map[string]RunRef
+}
+
+// NewRunTracker creates a new run tracker
+func NewRunTracker() *RunTracker {
+	return &RunTracker{runs: make(map[string]RunRef)}
+}
+
+// Track records a run
+func (t *RunTracker) Track(threadID, runID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.runs == nil {
+		t.runs = make(map[string]RunRef)
+	}
+	t.runs[runID] = RunRef{ThreadID: threadID, RunID: runID}
+}
+
+// Untrack forgets a run, typically once it reached a terminal status
+func (t *RunTracker) Untrack(runID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.runs, runID)
+}
+
+// Runs returns the tracked runs
+func (t *RunTracker) Runs() []RunRef {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	refs := make([]RunRef, 0, len(t.runs))
+	for _, ref := range t.runs {
+		refs = append(refs, ref)
+	}
+	return refs
+}
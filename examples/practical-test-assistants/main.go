@@ -157,7 +157,7 @@ func createFileSearchAssistant(service *assistants.Service) (*assistants.Assista
 	name := "Document Assistant"
 	instructions := "You are a document assistant that helps users find and analyze information in their files."
 
-	jsonFormat := assistants.ResponseFormat("auto")
+	jsonFormat := types.AutoResponseFormat()
 
 	return service.Create(&assistants.CreateAssistantRequest{
 		Model:        "gpt-4-1106-preview",
@@ -211,19 +211,15 @@ func listAssistantsWithPagination(service *assistants.Service) error {
 }
 
 func modifyAssistant(service *assistants.Service, assistantID string) (*assistants.Assistant, error) {
-	name := "Enhanced Math Tutor"
-	instructions := "You are an advanced math tutor who helps students understand complex mathematical concepts through interactive Python code examples and visualizations."
-	temp := 0.5 // More focused responses
-
-	return service.Modify(assistantID, &assistants.CreateAssistantRequest{
-		Name:         &name,
-		Instructions: &instructions,
-		Temperature:  &temp,
-		Metadata: types.Metadata{
+	return service.Modify(assistantID, &assistants.ModifyAssistantRequest{
+		Name:         types.Set("Enhanced Math Tutor"),
+		Instructions: types.Set("You are an advanced math tutor who helps students understand complex mathematical concepts through interactive Python code examples and visualizations."),
+		Temperature:  types.Set(0.5), // More focused responses
+		Metadata: types.Set(types.Metadata{
 			"expertise": "mathematics",
 			"level":     "expert",
 			"updated":   time.Now().Format(time.RFC3339),
-		},
+		}),
 	})
 }
 
@@ -233,7 +233,7 @@ func createAssistant(service *assistants.Service) (*assistants.Assistant, error)
 		},
 		Temperature:    &temperature,
 		TopP:           &topP,
-		ResponseFormat: "auto",
+		ResponseFormat: types.AutoResponseFormat(),
 		Metadata: types.Metadata{
 			"test_case": "run_steps_practical_test",
 		},
@@ -123,19 +123,18 @@ func createThreadWithTools(service *threads.Service) (*threads.Thread, error) {
 }
 
 func modifyThread(service *threads.Service, threadID string) (*threads.Thread, error) {
-	toolResources := &threads.ToolResources{
-		CodeInterpreter: &threads.CodeInterpreterResources{
-			FileIDs: []string{},
-		},
-	}
-
-	metadata := types.Metadata{
-		"purpose":  "advanced_analysis",
-		"priority": "high",
-		"status":   "in_progress",
-	}
-
-	return service.Modify(threadID, toolResources, metadata)
+	return service.Modify(threadID, &threads.ModifyThreadRequest{
+		ToolResources: types.Set(threads.ToolResources{
+			CodeInterpreter: &threads.CodeInterpreterResources{
+				FileIDs: []string{},
+			},
+		}),
+		Metadata: types.Set(types.Metadata{
+			"purpose":  "advanced_analysis",
+			"priority": "high",
+			"status":   "in_progress",
+		}),
+	})
 }
 
 func deleteThread(service *threads.Service, threadID string) error {
@@ -1,3 +1,5 @@
 module github.com/greenstorm5417/openai-assistants-go
 
 go 1.23.4
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -11,60 +11,81 @@ import (
 )
 
 type Assistant struct {
-	ID             string         `json:"id"`
-	Object         string         `json:"object"`
-	CreatedAt      int64          `json:"created_at"`
-	Name           *string        `json:"name,omitempty"`
-	Description    *string        `json:"description,omitempty"`
-	Model          string         `json:"model"`
-	Instructions   *string        `json:"instructions,omitempty"`
-	Tools          []Tool         `json:"tools"`
-	ToolResources  *ToolResources `json:"tool_resources,omitempty"`
-	Metadata       types.Metadata `json:"metadata,omitempty"`
-	Temperature    *float64       `json:"temperature,omitempty"`
-	TopP           *float64       `json:"top_p,omitempty"`
-	ResponseFormat ResponseFormat `json:"response_format,omitempty"`
+	ID              string          `json:"id"`
+	Object          string          `json:"object"`
+	CreatedAt       int64           `json:"created_at"`
+	Name            *string         `json:"name,omitempty"`
+	Description     *string         `json:"description,omitempty"`
+	Model           string          `json:"model"`
+	Instructions    *string         `json:"instructions,omitempty"`
+	Tools           []Tool          `json:"tools"`
+	ToolResources   *ToolResources  `json:"tool_resources,omitempty"`
+	Metadata        types.Metadata  `json:"metadata,omitempty"`
+	Temperature     *float64        `json:"temperature,omitempty"`
+	TopP            *float64        `json:"top_p,omitempty"`
+	ReasoningEffort *string         `json:"reasoning_effort,omitempty"`
+	ResponseFormat  *ResponseFormat `json:"response_format,omitempty"`
 }
 
-type Tool struct {
-	Type     string        `json:"type"`
-	Function *FunctionTool `json:"function,omitempty"`
-}
+// Tool is a tool enabled on an assistant
+type Tool = types.Tool
 
-type FunctionTool struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Parameters  any    `json:"parameters"`
-}
+// FunctionTool describes a function tool
+type FunctionTool = types.FunctionTool
 
 type ToolResources struct {
-	FileSearch *FileSearchResources `json:"file_search,omitempty"`
+	CodeInterpreter *CodeInterpreterResources `json:"code_interpreter,omitempty"`
+	FileSearch      *FileSearchResources      `json:"file_search,omitempty"`
+}
+
+// CodeInterpreterResources lists the files available to the code interpreter tool
+type CodeInterpreterResources struct {
+	FileIDs []string `json:"file_ids"`
 }
 
 type FileSearchResources struct {
 	VectorStoreIDs []string `json:"vector_store_ids"`
 }
 
-type ResponseFormat string
+// ResponseFormat is the format the assistant must output
+type ResponseFormat = types.ResponseFormat
 
-type JSONSchema struct {
-	Description string `json:"description,omitempty"`
-	Name        string `json:"name,omitempty"`
-	Schema      any    `json:"schema"`
-	Strict      *bool  `json:"strict,omitempty"`
-}
+// JSONSchema is the schema of a json_schema response format
+type JSONSchema = types.JSONSchema
 
 type CreateAssistantRequest struct {
-	Model          string         `json:"model"`
-	Name           *string        `json:"name,omitempty"`
-	Description    *string        `json:"description,omitempty"`
-	Instructions   *string        `json:"instructions,omitempty"`
-	Tools          []Tool         `json:"tools,omitempty"`
-	ToolResources  *ToolResources `json:"tool_resources,omitempty"`
-	Metadata       types.Metadata `json:"metadata,omitempty"`
-	Temperature    *float64       `json:"temperature,omitempty"`
-	TopP           *float64       `json:"top_p,omitempty"`
-	ResponseFormat ResponseFormat `json:"response_format,omitempty"`
+	Model           string          `json:"model"`
+	Name            *string         `json:"name,omitempty"`
+	Description     *string         `json:"description,omitempty"`
+	Instructions    *string         `json:"instructions,omitempty"`
+	Tools           []Tool          `json:"tools,omitempty"`
+	ToolResources   *ToolResources  `json:"tool_resources,omitempty"`
+	Metadata        types.Metadata  `json:"metadata,omitempty"`
+	Temperature     *float64        `json:"temperature,omitempty"`
+	TopP            *float64        `json:"top_p,omitempty"`
+	ReasoningEffort *string         `json:"reasoning_effort,omitempty"`
+	ResponseFormat  *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ModifyAssistantRequest represents the request to modify an assistant.
+// Unset fields are left unchanged; types.Null clears a field.
+type ModifyAssistantRequest struct {
+	Model           types.Optional[string]         `json:"model"`
+	Name            types.Optional[string]         `json:"name"`
+	Description     types.Optional[string]         `json:"description"`
+	Instructions    types.Optional[string]         `json:"instructions"`
+	Tools           types.Optional[[]Tool]         `json:"tools"`
+	ToolResources   types.Optional[ToolResources]  `json:"tool_resources"`
+	Metadata        types.Optional[types.Metadata] `json:"metadata"`
+	Temperature     types.Optional[float64]        `json:"temperature"`
+	TopP            types.Optional[float64]        `json:"top_p"`
+	ReasoningEffort types.Optional[string]         `json:"reasoning_effort"`
+	ResponseFormat  types.Optional[ResponseFormat] `json:"response_format"`
+}
+
+// MarshalJSON encodes only the fields that are set or null
+func (r ModifyAssistantRequest) MarshalJSON() ([]byte, error) {
+	return types.MarshalRequest(r)
 }
 
 type ListAssistantsResponse struct {
@@ -100,6 +121,14 @@ func New(c *client.Client) *Service {
 
 // Create creates a new assistant.
 func (s *Service) Create(req *CreateAssistantRequest) (*Assistant, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+	req, err := client.CheckModel(s.client, req, "")
+	if err != nil {
+		return nil, err
+	}
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -122,6 +151,10 @@ func (s *Service) Create(req *CreateAssistantRequest) (*Assistant, error) {
 
 // List returns a list of assistants.
 func (s *Service) List(params *ListAssistantsParams) (*ListAssistantsResponse, error) {
+	if err := s.client.Validate(params); err != nil {
+		return nil, err
+	}
+
 	url := s.client.BaseURL + "/assistants"
 	if params != nil {
 		query := make(map[string]string)
@@ -172,15 +205,30 @@ func (s *Service) Get(assistantID string) (*Assistant, error) {
 	req.Header.Set("OpenAI-Beta", "assistants=v2")
 
 	var assistant Assistant
-	if err := s.client.SendRequest(req, &assistant); err != nil {
+	if err := s.client.SendCachedRequest(req, client.ResourceAssistant, assistantID, &assistant); err != nil {
 		return nil, err
 	}
 
 	return &assistant, nil
 }
 
+// Refresh retrieves an assistant from the API, replacing any cached copy.
+// Use it when a stale assistant must not be returned.
+func (s *Service) Refresh(assistantID string) (*Assistant, error) {
+	s.client.Invalidate(client.ResourceAssistant, assistantID)
+	return s.Get(assistantID)
+}
+
 // Modify modifies an existing assistant.
-func (s *Service) Modify(assistantID string, req *CreateAssistantRequest) (*Assistant, error) {
+func (s *Service) Modify(assistantID string, req *ModifyAssistantRequest) (*Assistant, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+	req, err := client.CheckModel(s.client, req, assistantID)
+	if err != nil {
+		return nil, err
+	}
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -194,7 +242,9 @@ func (s *Service) Modify(assistantID string, req *CreateAssistantRequest) (*Assi
 	httpReq.Header.Set("OpenAI-Beta", "assistants=v2")
 
 	var assistant Assistant
-	if err := s.client.SendRequest(httpReq, &assistant); err != nil {
+	err = s.client.SendRequest(httpReq, &assistant)
+	s.client.Invalidate(client.ResourceAssistant, assistantID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -211,7 +261,9 @@ func (s *Service) Delete(assistantID string) (*DeleteAssistantResponse, error) {
 	req.Header.Set("OpenAI-Beta", "assistants=v2")
 
 	var response DeleteAssistantResponse
-	if err := s.client.SendRequest(req, &response); err != nil {
+	err = s.client.SendRequest(req, &response)
+	s.client.Invalidate(client.ResourceAssistant, assistantID)
+	if err != nil {
 		return nil, err
 	}
 
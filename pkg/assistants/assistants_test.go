@@ -2,11 +2,16 @@ package assistants
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
 )
 
 func TestCreateAssistant(t *testing.T) {
@@ -137,6 +142,66 @@ func TestGetAssistant(t *testing.T) {
 	}
 }
 
+func TestGetAssistantCached(t *testing.T) {
+	gets := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := "Before"
+		if r.Method == "GET" {
+			gets++
+			if gets > 1 {
+				name = "After"
+			}
+		}
+		json.NewEncoder(w).Encode(Assistant{ID: "asst_123", Name: &name})
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+		Cache:      client.NewCache(10, map[string]time.Duration{client.ResourceAssistant: time.Minute}),
+	}
+
+	service := New(c)
+
+	first, err := service.Get("asst_123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	*first.Name = "Mutated"
+
+	second, err := service.Get("asst_123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gets != 1 {
+		t.Errorf("Expected 1 GET request, got %d", gets)
+	}
+	if *second.Name != "Before" {
+		t.Errorf("Expected cached results not to share state, got %s", *second.Name)
+	}
+
+	if _, err := service.Modify("asst_123", &ModifyAssistantRequest{Name: types.Set("After")}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	third, err := service.Get("asst_123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gets != 2 || *third.Name != "After" {
+		t.Errorf("Expected Modify to invalidate the cached assistant, got %d requests and name %s", gets, *third.Name)
+	}
+
+	if _, err := service.Refresh("asst_123"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gets != 3 {
+		t.Errorf("Expected Refresh to bypass the cache, got %d requests", gets)
+	}
+}
+
 func TestModifyAssistant(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -160,10 +225,9 @@ func TestModifyAssistant(t *testing.T) {
 
 	service := New(c)
 
-	name := "Updated Assistant"
-	req := &CreateAssistantRequest{
-		Model: "gpt-4",
-		Name:  &name,
+	req := &ModifyAssistantRequest{
+		Model: types.Set("gpt-4"),
+		Name:  types.Set("Updated Assistant"),
 	}
 
 	assistant, err := service.Modify("asst_123", req)
@@ -208,3 +272,221 @@ func TestDeleteAssistant(t *testing.T) {
 		t.Error("Expected deleted to be true")
 	}
 }
+
+func TestToolJSON(t *testing.T) {
+	maxResults := 5
+	strict := true
+	tools := []Tool{
+		types.NewCodeInterpreterTool(),
+		types.NewFileSearchTool(&types.FileSearchTool{
+			MaxNumResults:  &maxResults,
+			RankingOptions: &types.RankingOptions{Ranker: "auto", ScoreThreshold: 0},
+		}),
+		types.NewFunctionTool(FunctionTool{Name: "lookup", Strict: &strict}),
+	}
+
+	data, err := json.Marshal(tools)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `[{"type":"code_interpreter"},` +
+		`{"type":"file_search","file_search":{"max_num_results":5,"ranking_options":{"ranker":"auto","score_threshold":0}}},` +
+		`{"type":"function","function":{"name":"lookup","strict":true}}]`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	var decoded []Tool
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *decoded[1].FileSearch.MaxNumResults != 5 || !*decoded[2].Function.Strict {
+		t.Errorf("Expected tool options to round-trip, got %+v", decoded)
+	}
+}
+
+func TestResponseFormatJSON(t *testing.T) {
+	strict := true
+	tests := []struct {
+		format *ResponseFormat
+		json   string
+	}{
+		{types.AutoResponseFormat(), `"auto"`},
+		{types.TextResponseFormat(), `{"type":"text"}`},
+		{types.JSONObjectResponseFormat(), `{"type":"json_object"}`},
+		{
+			types.JSONSchemaResponseFormat(JSONSchema{Name: "person", Schema: map[string]interface{}{"type": "object"}, Strict: &strict}),
+			`{"type":"json_schema","json_schema":{"name":"person","schema":{"type":"object"},"strict":true}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.format)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if string(data) != tt.json {
+			t.Errorf("Expected %s, got %s", tt.json, data)
+		}
+
+		var decoded ResponseFormat
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		again, _ := json.Marshal(decoded)
+		if string(again) != tt.json {
+			t.Errorf("Expected %s to round-trip, got %s", tt.json, again)
+		}
+	}
+
+	var assistant Assistant
+	data := `{"id":"asst_123","response_format":{"type":"json_schema","json_schema":{"name":"person","schema":{"type":"object"}}}}`
+	if err := json.Unmarshal([]byte(data), &assistant); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if assistant.ResponseFormat.Type != types.ResponseFormatJSONSchema || assistant.ResponseFormat.JSONSchema.Name != "person" {
+		t.Errorf("Expected json_schema format, got %+v", assistant.ResponseFormat)
+	}
+
+	if err := json.Unmarshal([]byte(`{"response_format":"auto"}`), &assistant); err != nil || assistant.ResponseFormat.Type != types.ResponseFormatAuto {
+		t.Errorf("Expected auto format, got %+v (%v)", assistant.ResponseFormat, err)
+	}
+}
+
+func TestModifyAssistantRequestJSON(t *testing.T) {
+	tests := []struct {
+		req  ModifyAssistantRequest
+		json string
+	}{
+		{ModifyAssistantRequest{}, `{}`},
+		{ModifyAssistantRequest{Instructions: types.Null[string]()}, `{"instructions":null}`},
+		{ModifyAssistantRequest{Name: types.Set(""), Temperature: types.Set(0.0)}, `{"name":"","temperature":0}`},
+		{
+			ModifyAssistantRequest{
+				Tools:         types.Set([]Tool{}),
+				ToolResources: types.Set(ToolResources{FileSearch: &FileSearchResources{VectorStoreIDs: []string{}}}),
+			},
+			`{"tools":[],"tool_resources":{"file_search":{"vector_store_ids":[]}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if string(data) != tt.json {
+			t.Errorf("Expected %s, got %s", tt.json, data)
+		}
+	}
+
+	var decoded ModifyAssistantRequest
+	if err := json.Unmarshal([]byte(`{"name":"Tutor","instructions":null}`), &decoded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if name, ok := decoded.Name.Value(); !ok || name != "Tutor" {
+		t.Errorf("Expected name to be set, got %+v", decoded.Name)
+	}
+	if !decoded.Instructions.IsNull() {
+		t.Errorf("Expected instructions to be null, got %+v", decoded.Instructions)
+	}
+	if decoded.Model.IsPresent() {
+		t.Errorf("Expected model to be unset, got %+v", decoded.Model)
+	}
+}
+
+func TestCreateAssistantValidation(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		json.NewEncoder(w).Encode(Assistant{ID: "asst_123"})
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:          server.URL,
+		APIKey:           "test-key",
+		HTTPClient:       server.Client(),
+		ValidateRequests: true,
+	}
+	service := New(c)
+
+	metadata := types.Metadata{strings.Repeat("k", 65): "v", "long": strings.Repeat("v", 513)}
+	for i := 0; i < 15; i++ {
+		metadata[fmt.Sprintf("key%d", i)] = "v"
+	}
+	temperature := 2.5
+	effort := "extreme"
+	req := &CreateAssistantRequest{
+		Temperature:     &temperature,
+		ReasoningEffort: &effort,
+		Metadata:        metadata,
+		Tools: []Tool{
+			types.NewFunctionTool(FunctionTool{Name: "get weather"}),
+			types.NewFunctionTool(FunctionTool{Name: "lookup"}),
+			types.NewFunctionTool(FunctionTool{Name: "lookup"}),
+			{Type: "browser"},
+		},
+	}
+
+	_, err := service.Create(req)
+	if called {
+		t.Error("Expected invalid request not to be sent")
+	}
+
+	var ve *types.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+	paths := make(map[string]bool)
+	for _, fe := range ve.Errors {
+		paths[fe.Path] = true
+	}
+	for _, path := range []string{
+		"model",
+		"temperature",
+		"reasoning_effort",
+		"metadata",
+		`metadata["` + strings.Repeat("k", 65) + `"]`,
+		`metadata["long"]`,
+		"tools[0].function.name",
+		"tools[2].function.name",
+		"tools[3].type",
+	} {
+		if !paths[path] {
+			t.Errorf("Expected error at %s, got %v", path, err)
+		}
+	}
+
+	c.ValidateRequests = false
+	if _, err := service.Create(req); err != nil || !called {
+		t.Errorf("Expected request to be sent with validation disabled, got %v", err)
+	}
+}
+
+func TestModifyAssistantValidation(t *testing.T) {
+	topP := 1.5
+	req := &ModifyAssistantRequest{
+		Model: types.Null[string](),
+		TopP:  types.Set(topP),
+		ResponseFormat: types.Set(ResponseFormat{
+			Type:       types.ResponseFormatJSONSchema,
+			JSONSchema: &JSONSchema{Name: "has spaces"},
+		}),
+	}
+
+	err := req.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, path := range []string{"model", "top_p", "response_format.json_schema.name"} {
+		if !strings.Contains(err.Error(), path+":") {
+			t.Errorf("Expected error at %s, got %v", path, err)
+		}
+	}
+
+	if err := (&ModifyAssistantRequest{Instructions: types.Null[string]()}).Validate(); err != nil {
+		t.Errorf("Expected clearing instructions to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package assistants
+
+import "github.com/greenstorm5417/openai-assistants-go/pkg/types"
+
+// TargetModel returns the assistant's model
+func (r *CreateAssistantRequest) TargetModel() string {
+	if r == nil {
+		return ""
+	}
+	return r.Model
+}
+
+// Conform reports the model-dependent fields of the request and returns a copy
+// with the fields c strips cleared
+func (r *CreateAssistantRequest) Conform(c *types.Conformer) types.ModelRequest {
+	if r == nil {
+		return r
+	}
+
+	out := *r
+	if c.Param("temperature", out.Temperature != nil, c.Caps.Temperature) {
+		out.Temperature = nil
+	}
+	if c.Param("top_p", out.TopP != nil, c.Caps.TopP) {
+		out.TopP = nil
+	}
+	if c.Param("reasoning_effort", out.ReasoningEffort != nil, c.Caps.ReasoningEffort) {
+		out.ReasoningEffort = nil
+	}
+	if c.ResponseFormat("response_format", out.ResponseFormat) {
+		out.ResponseFormat = nil
+	}
+	out.Tools = c.Tools("tools", out.Tools)
+	return &out
+}
+
+// TargetModel returns the model the request switches the assistant to, or "" when it keeps its model
+func (r *ModifyAssistantRequest) TargetModel() string {
+	if r == nil {
+		return ""
+	}
+	model, _ := r.Model.Value()
+	return model
+}
+
+// Conform reports the model-dependent fields set on the request and returns a copy
+// with the fields c strips cleared
+func (r *ModifyAssistantRequest) Conform(c *types.Conformer) types.ModelRequest {
+	if r == nil {
+		return r
+	}
+
+	out := *r
+	if _, ok := out.Temperature.Value(); c.Param("temperature", ok, c.Caps.Temperature) {
+		out.Temperature = types.Optional[float64]{}
+	}
+	if _, ok := out.TopP.Value(); c.Param("top_p", ok, c.Caps.TopP) {
+		out.TopP = types.Optional[float64]{}
+	}
+	if _, ok := out.ReasoningEffort.Value(); c.Param("reasoning_effort", ok, c.Caps.ReasoningEffort) {
+		out.ReasoningEffort = types.Optional[string]{}
+	}
+	if format, ok := out.ResponseFormat.Value(); ok && c.ResponseFormat("response_format", &format) {
+		out.ResponseFormat = types.Optional[ResponseFormat]{}
+	}
+	if tools, ok := out.Tools.Value(); ok {
+		out.Tools = types.Set(c.Tools("tools", tools))
+	}
+	return &out
+}
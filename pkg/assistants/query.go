@@ -0,0 +1,206 @@
+package assistants
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// ErrNotFound is returned by FindFirst when no assistant matches
+var ErrNotFound = errors.New("assistant not found")
+
+// Query selects assistants. Every field that is set must match; the zero Query matches every assistant.
+type Query struct {
+	// Name matches the exact name
+	Name string
+	// NamePrefix matches names starting with the prefix
+	NamePrefix string
+	// NamePattern matches names against a regular expression
+	NamePattern *regexp.Regexp
+	// Model matches the exact model
+	Model string
+	// ToolTypes lists tool types the assistant must all have, such as types.ToolTypeFileSearch
+	ToolTypes []string
+	// Metadata lists predicates the assistant's metadata must all satisfy
+	Metadata []MetadataPredicate
+}
+
+// MetadataPredicate matches assistant metadata
+type MetadataPredicate func(types.Metadata) bool
+
+// MetadataEquals matches metadata where key is set to value
+func MetadataEquals(key, value string) MetadataPredicate {
+	return func(m types.Metadata) bool {
+		v, ok := m[key]
+		return ok && fmt.Sprint(v) == value
+	}
+}
+
+// MetadataHasKey matches metadata where key is set
+func MetadataHasKey(key string) MetadataPredicate {
+	return func(m types.Metadata) bool {
+		_, ok := m[key]
+		return ok
+	}
+}
+
+// MetadataMatches matches metadata where the value of key matches pattern
+func MetadataMatches(key string, pattern *regexp.Regexp) MetadataPredicate {
+	return func(m types.Metadata) bool {
+		v, ok := m[key]
+		return ok && pattern.MatchString(fmt.Sprint(v))
+	}
+}
+
+// Match reports whether the assistant satisfies the query
+func (q *Query) Match(a *Assistant) bool {
+	if q == nil {
+		return true
+	}
+
+	var name string
+	if a.Name != nil {
+		name = *a.Name
+	}
+	if q.Name != "" && name != q.Name {
+		return false
+	}
+	if q.NamePrefix != "" && !strings.HasPrefix(name, q.NamePrefix) {
+		return false
+	}
+	if q.NamePattern != nil && !q.NamePattern.MatchString(name) {
+		return false
+	}
+	if q.Model != "" && a.Model != q.Model {
+		return false
+	}
+	for _, toolType := range q.ToolTypes {
+		if !hasTool(a.Tools, toolType) {
+			return false
+		}
+	}
+	for _, pred := range q.Metadata {
+		if !pred(a.Metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+// empty reports whether the query sets no fields
+func (q *Query) empty() bool {
+	return q.Name == "" && q.NamePrefix == "" && q.NamePattern == nil && q.Model == "" &&
+		len(q.ToolTypes) == 0 && len(q.Metadata) == 0
+}
+
+func hasTool(tools []Tool, toolType string) bool {
+	for _, tool := range tools {
+		if tool.Type == toolType {
+			return true
+		}
+	}
+	return false
+}
+
+// Each pages through every assistant, newest first, and calls fn for each one until fn returns false
+func (s *Service) Each(fn func(*Assistant) bool) error {
+	limit := 100
+	var after *string
+	for {
+		resp, err := s.List(&ListAssistantsParams{Limit: &limit, After: after})
+		if err != nil {
+			return err
+		}
+		for i := range resp.Data {
+			if !fn(&resp.Data[i]) {
+				return nil
+			}
+		}
+		if !resp.HasMore || resp.LastID == "" {
+			return nil
+		}
+		last := resp.LastID
+		after = &last
+	}
+}
+
+// Find returns every assistant matching q, newest first
+func (s *Service) Find(q *Query) ([]Assistant, error) {
+	var matches []Assistant
+	err := s.Each(func(a *Assistant) bool {
+		if q.Match(a) {
+			matches = append(matches, *a)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// FindFirst returns the newest assistant matching q, or ErrNotFound. It stops paging at the first match.
+func (s *Service) FindFirst(q *Query) (*Assistant, error) {
+	var match *Assistant
+	err := s.Each(func(a *Assistant) bool {
+		if q.Match(a) {
+			found := *a
+			match = &found
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return nil, ErrNotFound
+	}
+	return match, nil
+}
+
+// FindOrCreate returns the newest assistant matching q, or creates one from req when none does,
+// so services can bootstrap their assistants idempotently at startup. A nil q matches req's
+// name, model and metadata, so req must then have a name or metadata; a model alone would
+// match any assistant using it. A non-nil q must set at least one field, since the zero
+// Query matches every assistant. The boolean reports whether the assistant was created.
+// Concurrent callers can still both create an assistant; run bootstrapping from a single process.
+func (s *Service) FindOrCreate(q *Query, req *CreateAssistantRequest) (*Assistant, bool, error) {
+	if q == nil {
+		if req == nil || ((req.Name == nil || *req.Name == "") && len(req.Metadata) == 0) {
+			return nil, false, errors.New("find or create: a query, or a request with a name or metadata, is required to identify the assistant")
+		}
+		q = QueryFor(req)
+	} else if q.empty() {
+		return nil, false, errors.New("find or create: the query sets no fields and would match any assistant")
+	}
+
+	a, err := s.FindFirst(q)
+	if err == nil {
+		return a, false, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, false, err
+	}
+
+	a, err = s.Create(req)
+	if err != nil {
+		return nil, false, err
+	}
+	return a, true, nil
+}
+
+// QueryFor returns a query matching assistants with the name, model and metadata of req
+func QueryFor(req *CreateAssistantRequest) *Query {
+	q := &Query{Model: req.Model}
+	if req.Name != nil {
+		q.Name = *req.Name
+	}
+	for key, value := range req.Metadata {
+		q.Metadata = append(q.Metadata, MetadataEquals(key, fmt.Sprint(value)))
+	}
+	return q
+}
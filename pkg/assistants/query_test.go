@@ -0,0 +1,158 @@
+package assistants
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+func newQueryServer(t *testing.T, all []Assistant, pages *int, created *CreateAssistantRequest) *Service {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			json.NewDecoder(r.Body).Decode(created)
+			json.NewEncoder(w).Encode(Assistant{ID: "asst_new", Name: created.Name, Model: created.Model})
+			return
+		}
+
+		// Serve two assistants per page
+		*pages++
+		start := 0
+		if after := r.URL.Query().Get("after"); after != "" {
+			for i, a := range all {
+				if a.ID == after {
+					start = i + 1
+				}
+			}
+		}
+		end := start + 2
+		if end > len(all) {
+			end = len(all)
+		}
+		resp := ListAssistantsResponse{Object: "list", Data: all[start:end], HasMore: end < len(all)}
+		if end > start {
+			resp.LastID = all[end-1].ID
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	return New(&client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	})
+}
+
+func TestFind(t *testing.T) {
+	name := func(s string) *string { return &s }
+	all := []Assistant{
+		{ID: "asst_1", Name: name("support-en"), Model: "gpt-4o", Tools: []Tool{types.NewFileSearchTool(nil)}, Metadata: types.Metadata{"team": "support"}},
+		{ID: "asst_2", Name: name("sales"), Model: "gpt-4o"},
+		{ID: "asst_3", Name: name("support-de"), Model: "gpt-4o-mini", Metadata: types.Metadata{"team": "support", "tier": "2"}},
+		{ID: "asst_4", Model: "gpt-4o"},
+		{ID: "asst_5", Name: name("support-fr"), Model: "gpt-4o", Tools: []Tool{types.NewCodeInterpreterTool(), types.NewFileSearchTool(nil)}},
+	}
+
+	pages := 0
+	service := newQueryServer(t, all, &pages, nil)
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  []string
+	}{
+		{"all", nil, []string{"asst_1", "asst_2", "asst_3", "asst_4", "asst_5"}},
+		{"exact name", &Query{Name: "sales"}, []string{"asst_2"}},
+		{"prefix", &Query{NamePrefix: "support-"}, []string{"asst_1", "asst_3", "asst_5"}},
+		{"pattern and model", &Query{NamePattern: regexp.MustCompile(`-(en|fr)$`), Model: "gpt-4o"}, []string{"asst_1", "asst_5"}},
+		{"tool types", &Query{ToolTypes: []string{types.ToolTypeFileSearch, types.ToolTypeCodeInterpreter}}, []string{"asst_5"}},
+		{"metadata", &Query{Metadata: []MetadataPredicate{MetadataEquals("team", "support"), MetadataHasKey("tier")}}, []string{"asst_3"}},
+		{"metadata pattern", &Query{Metadata: []MetadataPredicate{MetadataMatches("tier", regexp.MustCompile(`^[0-9]+$`))}}, []string{"asst_3"}},
+		{"none", &Query{Model: "o3-mini"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := service.Find(tt.query)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var ids []string
+			for _, a := range matches {
+				ids = append(ids, a.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, ids)
+				}
+			}
+		})
+	}
+
+	pages = 0
+	first, err := service.FindFirst(&Query{NamePrefix: "support-"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.ID != "asst_1" || pages != 1 {
+		t.Errorf("Expected asst_1 from the first page, got %s after %d pages", first.ID, pages)
+	}
+
+	if _, err := service.FindFirst(&Query{Name: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFindOrCreate(t *testing.T) {
+	name := "support-en"
+	all := []Assistant{
+		{ID: "asst_1", Name: &name, Model: "gpt-4o", Metadata: types.Metadata{"env": "prod"}},
+	}
+
+	pages := 0
+	var created CreateAssistantRequest
+	service := newQueryServer(t, all, &pages, &created)
+
+	req := &CreateAssistantRequest{Model: "gpt-4o", Name: &name, Metadata: types.Metadata{"env": "prod"}}
+	a, isNew, err := service.FindOrCreate(nil, req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if isNew || a.ID != "asst_1" {
+		t.Errorf("Expected the existing assistant, got %s (created %v)", a.ID, isNew)
+	}
+
+	req.Metadata = types.Metadata{"env": "staging"}
+	a, isNew, err = service.FindOrCreate(nil, req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !isNew || a.ID != "asst_new" || created.Metadata["env"] != "staging" {
+		t.Errorf("Expected a new assistant from the request, got %s (created %v, request %+v)", a.ID, isNew, created)
+	}
+
+	a, isNew, err = service.FindOrCreate(&Query{Name: name}, req)
+	if err != nil || isNew || a.ID != "asst_1" {
+		t.Errorf("Expected the explicit query to find asst_1, got %v (created %v, %v)", a, isNew, err)
+	}
+
+	created = CreateAssistantRequest{}
+	if _, _, err := service.FindOrCreate(nil, &CreateAssistantRequest{Model: "gpt-4o"}); err == nil {
+		t.Error("Expected an error for a request without a name or metadata")
+	}
+	if _, _, err := service.FindOrCreate(&Query{}, req); err == nil {
+		t.Error("Expected an error for a query that sets no fields")
+	}
+	if created.Model != "" {
+		t.Errorf("Expected no assistant to be created, got %+v", created)
+	}
+}
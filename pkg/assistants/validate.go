@@ -0,0 +1,107 @@
+package assistants
+
+import "github.com/greenstorm5417/openai-assistants-go/pkg/types"
+
+// Field length limits of assistants
+const (
+	MaxNameLength         = 256
+	MaxDescriptionLength  = 512
+	MaxInstructionsLength = 256000
+)
+
+// Validate checks the request against the API's limits and returns every problem found
+func (r *CreateAssistantRequest) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var v types.Validator
+	v.Required("model", r.Model)
+	if r.Name != nil {
+		v.MaxLength("name", *r.Name, MaxNameLength)
+	}
+	if r.Description != nil {
+		v.MaxLength("description", *r.Description, MaxDescriptionLength)
+	}
+	if r.Instructions != nil {
+		v.MaxLength("instructions", *r.Instructions, MaxInstructionsLength)
+	}
+	v.Tools("tools", r.Tools)
+	validateToolResources(&v, r.ToolResources)
+	v.Metadata("metadata", r.Metadata)
+	v.Range("temperature", r.Temperature, 0, 2)
+	v.Range("top_p", r.TopP, 0, 1)
+	if r.ReasoningEffort != nil {
+		v.ReasoningEffort("reasoning_effort", *r.ReasoningEffort)
+	}
+	v.ResponseFormat("response_format", r.ResponseFormat)
+	return v.Err()
+}
+
+// Validate checks the fields that are set against the API's limits and returns every problem found
+func (r *ModifyAssistantRequest) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var v types.Validator
+	if model, ok := r.Model.Value(); ok {
+		v.Required("model", model)
+	} else if r.Model.IsNull() {
+		v.Addf("model", "cannot be cleared")
+	}
+	if name, ok := r.Name.Value(); ok {
+		v.MaxLength("name", name, MaxNameLength)
+	}
+	if description, ok := r.Description.Value(); ok {
+		v.MaxLength("description", description, MaxDescriptionLength)
+	}
+	if instructions, ok := r.Instructions.Value(); ok {
+		v.MaxLength("instructions", instructions, MaxInstructionsLength)
+	}
+	if tools, ok := r.Tools.Value(); ok {
+		v.Tools("tools", tools)
+	}
+	if resources, ok := r.ToolResources.Value(); ok {
+		validateToolResources(&v, &resources)
+	}
+	if metadata, ok := r.Metadata.Value(); ok {
+		v.Metadata("metadata", metadata)
+	}
+	if temperature, ok := r.Temperature.Value(); ok {
+		v.Range("temperature", &temperature, 0, 2)
+	}
+	if topP, ok := r.TopP.Value(); ok {
+		v.Range("top_p", &topP, 0, 1)
+	}
+	if effort, ok := r.ReasoningEffort.Value(); ok {
+		v.ReasoningEffort("reasoning_effort", effort)
+	}
+	if format, ok := r.ResponseFormat.Value(); ok {
+		v.ResponseFormat("response_format", &format)
+	}
+	return v.Err()
+}
+
+// Validate checks the list parameters
+func (p *ListAssistantsParams) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	var v types.Validator
+	v.ListParams(p.Limit, p.Order)
+	return v.Err()
+}
+
+func validateToolResources(v *types.Validator, r *ToolResources) {
+	if r == nil {
+		return
+	}
+	if r.CodeInterpreter != nil && len(r.CodeInterpreter.FileIDs) > 20 {
+		v.Addf("tool_resources.code_interpreter.file_ids", "must have at most 20 files, got %d", len(r.CodeInterpreter.FileIDs))
+	}
+	if r.FileSearch != nil && len(r.FileSearch.VectorStoreIDs) > 1 {
+		v.Addf("tool_resources.file_search.vector_store_ids", "must have at most 1 vector store, got %d", len(r.FileSearch.VectorStoreIDs))
+	}
+}
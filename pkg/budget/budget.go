@@ -0,0 +1,360 @@
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+const (
+	defaultMetadataKey     = "tenant_id"
+	defaultCompletionShare = 0.25
+
+	// minRunTokens is the smallest max_prompt_tokens and max_completion_tokens the API accepts
+	minRunTokens = 256
+	// maxRecorded bounds the run IDs remembered to avoid counting a run twice
+	maxRecorded = 10000
+)
+
+// ErrBudgetExceeded is matched by errors.Is for every ExceededError
+var ErrBudgetExceeded = errors.New("token budget exceeded")
+
+// ErrMissingKey is returned when a run or request carries no budget key in its metadata
+var ErrMissingKey = errors.New("budget key missing from metadata")
+
+// ExceededError is returned when a key has no tokens left for a new run
+type ExceededError struct {
+	Key      string
+	Limit    int
+	Consumed int
+	// Reserved counts the tokens held by runs that have not been recorded yet
+	Reserved  int
+	Remaining int
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("token budget exceeded for %s: consumed %d and reserved %d of %d tokens", e.Key, e.Consumed, e.Reserved, e.Limit)
+}
+
+// Is reports whether target is ErrBudgetExceeded
+func (e *ExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// Manager enforces a token allowance per key, such as a tenant ID stored in run metadata
+type Manager struct {
+	// MetadataKey is the metadata key holding the budget key. Defaults to "tenant_id".
+	MetadataKey string
+	// DefaultLimit applies to keys without their own limit. Zero means unlimited.
+	DefaultLimit int
+	// MinTokens is the smallest allowance a run may start with. Smaller allowances refuse the run.
+	// Runs always need at least 256 prompt and 256 completion tokens, the smallest caps the API accepts.
+	MinTokens int
+	// CompletionShare is the part of the remaining allowance given to completion tokens
+	// when a request sets neither token cap. Defaults to 0.25.
+	CompletionShare float64
+
+	store Store
+
+	mu       sync.Mutex
+	limits   map[string]int
+	reserved map[string]int
+	bound    map[string]*Reservation
+	recorded recentSet
+}
+
+// Reservation holds tokens of a key for a run until its usage is recorded.
+// Reservations are kept by the Manager, so a shared Store does not share them between processes.
+type Reservation struct {
+	Key    string
+	Tokens int
+
+	m     *Manager
+	runID string
+	done  bool
+}
+
+// Bind associates the reservation with the created run, so Record settles it
+func (r *Reservation) Bind(runID string) {
+	if r == nil {
+		return
+	}
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if r.done {
+		return
+	}
+	r.runID = runID
+	r.m.bound[runID] = r
+}
+
+// Release returns the reserved tokens, for example when the run could not be created
+func (r *Reservation) Release() {
+	if r == nil {
+		return
+	}
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	r.m.release(r)
+}
+
+// NewManager creates a new budget manager backed by the store
+func NewManager(store Store) *Manager {
+	return &Manager{
+		MetadataKey:     defaultMetadataKey,
+		MinTokens:       1,
+		CompletionShare: defaultCompletionShare,
+		store:           store,
+		limits:          make(map[string]int),
+		reserved:        make(map[string]int),
+		bound:           make(map[string]*Reservation),
+		recorded:        newRecentSet(maxRecorded),
+	}
+}
+
+// SetLimit sets the token allowance of a key
+func (m *Manager) SetLimit(key string, tokens int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits[key] = tokens
+}
+
+// Limit returns the token allowance of a key and whether it is limited at all
+func (m *Manager) Limit(key string) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limit(key)
+}
+
+// limit returns the allowance of a key; the caller must hold m.mu
+func (m *Manager) limit(key string) (int, bool) {
+	if limit, ok := m.limits[key]; ok {
+		return limit, true
+	}
+	return m.DefaultLimit, m.DefaultLimit > 0
+}
+
+// Remaining returns the tokens left for a key after consumed and reserved tokens. Unlimited keys report -1.
+func (m *Manager) Remaining(key string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	limit, limited := m.limit(key)
+	if !limited {
+		return -1, nil
+	}
+	consumed, err := m.store.Consumed(key)
+	if err != nil {
+		return 0, err
+	}
+	return remainingOf(limit, consumed, m.reserved[key]), nil
+}
+
+func remainingOf(limit, consumed, reserved int) int {
+	return max(limit-consumed-reserved, 0)
+}
+
+// Record adds the usage of a finished run to the key in its metadata and settles
+// the run's reservation. Each run ID is only counted once; terminal runs without
+// usage only release their reservation.
+func (m *Manager) Record(run *runs.Run) error {
+	if !run.Status.IsTerminal() {
+		return nil
+	}
+
+	m.mu.Lock()
+	if m.recorded.has(run.ID) {
+		m.mu.Unlock()
+		return nil
+	}
+	res := m.bound[run.ID]
+	if run.Usage == nil {
+		if res != nil {
+			m.release(res)
+		}
+		m.mu.Unlock()
+		return nil
+	}
+
+	var key string
+	if res != nil {
+		key = res.Key
+	} else if k, ok := m.keyOf(run.Metadata); ok {
+		key = k
+	} else {
+		m.mu.Unlock()
+		return ErrMissingKey
+	}
+	m.recorded.add(run.ID)
+	m.mu.Unlock()
+
+	if _, err := m.store.Add(key, run.Usage.TotalTokens); err != nil {
+		m.mu.Lock()
+		m.recorded.remove(run.ID)
+		m.mu.Unlock()
+		return err
+	}
+
+	if res != nil {
+		res.Release()
+	}
+	return nil
+}
+
+// Apply checks the allowance of the key in the request metadata, clamps
+// MaxPromptTokens and MaxCompletionTokens so the run cannot exceed it and reserves
+// those tokens. Bind the reservation to the created run so Record settles it, or
+// Release it if the run is not created. Unlimited keys get a nil reservation, whose
+// methods do nothing. It returns an *ExceededError when the remaining allowance is
+// below MinTokens or too small for the API's minimum caps.
+func (m *Manager) Apply(req *runs.CreateRunRequest) (*Reservation, error) {
+	key, ok := m.keyOf(req.Metadata)
+	if !ok {
+		return nil, ErrMissingKey
+	}
+	return m.ApplyKey(key, req)
+}
+
+// ApplyKey is like Apply but takes the budget key explicitly
+func (m *Manager) ApplyKey(key string, req *runs.CreateRunRequest) (*Reservation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	limit, limited := m.limit(key)
+	if !limited {
+		return nil, nil
+	}
+
+	consumed, err := m.store.Consumed(key)
+	if err != nil {
+		return nil, err
+	}
+	reserved := m.reserved[key]
+	remaining := remainingOf(limit, consumed, reserved)
+	if remaining < m.MinTokens || remaining < 2*minRunTokens {
+		return nil, &ExceededError{Key: key, Limit: limit, Consumed: consumed, Reserved: reserved, Remaining: remaining}
+	}
+
+	prompt, completion := m.split(remaining, req.MaxPromptTokens, req.MaxCompletionTokens)
+	req.MaxPromptTokens = &prompt
+	req.MaxCompletionTokens = &completion
+
+	res := &Reservation{Key: key, Tokens: prompt + completion, m: m}
+	m.reserved[key] += res.Tokens
+	return res, nil
+}
+
+// Create applies the budget to the request and creates the run, binding the reservation to it
+func (m *Manager) Create(service *runs.Service, threadID string, req *runs.CreateRunRequest) (*runs.Run, error) {
+	res, err := m.Apply(req)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := service.Create(threadID, req)
+	if err != nil {
+		res.Release()
+		return nil, err
+	}
+	res.Bind(run.ID)
+	return run, nil
+}
+
+// release returns the tokens of a reservation; the caller must hold m.mu
+func (m *Manager) release(r *Reservation) {
+	if r.done {
+		return
+	}
+	r.done = true
+	m.reserved[r.Key] -= r.Tokens
+	if m.reserved[r.Key] <= 0 {
+		delete(m.reserved, r.Key)
+	}
+	if r.runID != "" {
+		delete(m.bound, r.runID)
+	}
+}
+
+// split divides the remaining allowance between prompt and completion tokens,
+// keeping existing caps when they already fit. Caps that are adjusted are at least
+// minRunTokens; remaining must be at least twice that.
+func (m *Manager) split(remaining int, prompt, completion *int) (int, int) {
+	share := m.CompletionShare
+	if share <= 0 || share >= 1 {
+		share = defaultCompletionShare
+	}
+
+	var c int
+	switch {
+	case prompt != nil && completion != nil:
+		if *prompt+*completion <= remaining {
+			return *prompt, *completion
+		}
+		c = int(float64(remaining) * float64(*completion) / float64(*prompt+*completion))
+	case completion != nil:
+		c = min(*completion, remaining)
+		if c == remaining {
+			c = int(float64(remaining) * share)
+		}
+	case prompt != nil:
+		p := min(*prompt, remaining)
+		if p == remaining {
+			p = remaining - int(float64(remaining)*share)
+		}
+		c = remaining - p
+	default:
+		c = int(float64(remaining) * share)
+	}
+	return fit(remaining, c)
+}
+
+// fit gives completion tokens c and prompt tokens the rest of remaining, keeping both at least minRunTokens
+func fit(remaining, c int) (int, int) {
+	c = min(max(c, minRunTokens), remaining-minRunTokens)
+	return remaining - c, c
+}
+
+func (m *Manager) keyOf(metadata map[string]interface{}) (string, bool) {
+	name := m.MetadataKey
+	if name == "" {
+		name = defaultMetadataKey
+	}
+	v, ok := metadata[name]
+	if !ok || v == nil {
+		return "", false
+	}
+	key := fmt.Sprint(v)
+	return key, key != ""
+}
+
+// recentSet remembers the most recently added IDs up to a fixed size
+type recentSet struct {
+	// ids maps each ID to its slot in order
+	ids   map[string]int
+	order []string
+	next  int
+}
+
+func newRecentSet(size int) recentSet {
+	return recentSet{ids: make(map[string]int), order: make([]string, size)}
+}
+
+func (s *recentSet) has(id string) bool {
+	_, ok := s.ids[id]
+	return ok
+}
+
+func (s *recentSet) add(id string) {
+	if old := s.order[s.next]; old != "" && s.ids[old] == s.next {
+		delete(s.ids, old)
+	}
+	s.order[s.next] = id
+	s.ids[id] = s.next
+	s.next = (s.next + 1) % len(s.order)
+}
+
+func (s *recentSet) remove(id string) {
+	delete(s.ids, id)
+}
@@ -0,0 +1,201 @@
+package budget
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestApply(t *testing.T) {
+	m := NewManager(NewMemoryStore())
+	m.SetLimit("tenant_a", 10000)
+
+	req := &runs.CreateRunRequest{AssistantID: "asst_123", Metadata: types.Metadata{"tenant_id": "tenant_a"}}
+	res, err := m.Apply(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *req.MaxPromptTokens != 7500 || *req.MaxCompletionTokens != 2500 {
+		t.Errorf("Expected caps 7500/2500, got %d/%d", *req.MaxPromptTokens, *req.MaxCompletionTokens)
+	}
+	res.Release()
+
+	req = &runs.CreateRunRequest{
+		AssistantID:         "asst_123",
+		Metadata:            types.Metadata{"tenant_id": "tenant_a"},
+		MaxPromptTokens:     intPtr(2000),
+		MaxCompletionTokens: intPtr(500),
+	}
+	if _, err := m.Apply(req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *req.MaxPromptTokens != 2000 || *req.MaxCompletionTokens != 500 {
+		t.Errorf("Expected caps that fit to be kept, got %d/%d", *req.MaxPromptTokens, *req.MaxCompletionTokens)
+	}
+
+	unlimited := &runs.CreateRunRequest{AssistantID: "asst_123", Metadata: types.Metadata{"tenant_id": "tenant_b"}}
+	if res, err := m.Apply(unlimited); err != nil || res != nil || unlimited.MaxPromptTokens != nil {
+		t.Errorf("Expected unlimited tenant to be left alone, got %v", err)
+	}
+
+	if _, err := m.Apply(&runs.CreateRunRequest{AssistantID: "asst_123"}); !errors.Is(err, ErrMissingKey) {
+		t.Errorf("Expected ErrMissingKey, got %v", err)
+	}
+}
+
+func TestRecordAndRefuse(t *testing.T) {
+	m := NewManager(NewMemoryStore())
+	m.SetLimit("tenant_a", 1000)
+
+	run := &runs.Run{
+		ID:       "run_123",
+		Status:   runs.StatusCompleted,
+		Metadata: types.Metadata{"tenant_id": "tenant_a"},
+		Usage:    &runs.Usage{PromptTokens: 800, CompletionTokens: 200, TotalTokens: 1000},
+	}
+	if err := m.Record(run); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := m.Record(run); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	remaining, err := m.Remaining("tenant_a")
+	if err != nil || remaining != 0 {
+		t.Fatalf("Expected 0 tokens remaining, got %d (%v)", remaining, err)
+	}
+
+	_, err = m.Apply(&runs.CreateRunRequest{AssistantID: "asst_123", Metadata: types.Metadata{"tenant_id": "tenant_a"}})
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("Expected ErrBudgetExceeded, got %v", err)
+	}
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || exceeded.Key != "tenant_a" || exceeded.Consumed != 1000 {
+		t.Errorf("Unexpected exceeded error: %+v", exceeded)
+	}
+}
+
+func TestReservations(t *testing.T) {
+	m := NewManager(NewMemoryStore())
+	m.SetLimit("tenant_a", 6000)
+
+	newRequest := func() *runs.CreateRunRequest {
+		return &runs.CreateRunRequest{
+			AssistantID:         "asst_123",
+			Metadata:            types.Metadata{"tenant_id": "tenant_a"},
+			MaxPromptTokens:     intPtr(2000),
+			MaxCompletionTokens: intPtr(1000),
+		}
+	}
+
+	// Concurrent runs cannot share the same allowance
+	first, err := m.Apply(newRequest())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, err := m.Apply(newRequest())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, err = m.Apply(newRequest())
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || exceeded.Reserved != 6000 {
+		t.Fatalf("Expected the reserved tokens to refuse a third run, got %v", err)
+	}
+
+	// A run that was not created returns its tokens
+	second.Release()
+	second.Release()
+	if remaining, _ := m.Remaining("tenant_a"); remaining != 3000 {
+		t.Errorf("Expected 3000 tokens remaining after release, got %d", remaining)
+	}
+
+	// Recording settles the reservation with the actual usage
+	first.Bind("run_1")
+	run := &runs.Run{ID: "run_1", Status: runs.StatusCompleted, Usage: &runs.Usage{TotalTokens: 1200}}
+	if err := m.Record(run); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if remaining, _ := m.Remaining("tenant_a"); remaining != 4800 {
+		t.Errorf("Expected 4800 tokens remaining after recording, got %d", remaining)
+	}
+
+	// A terminal run without usage only releases its reservation
+	third, _ := m.Apply(newRequest())
+	third.Bind("run_2")
+	if err := m.Record(&runs.Run{ID: "run_2", Status: runs.StatusFailed}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if remaining, _ := m.Remaining("tenant_a"); remaining != 4800 {
+		t.Errorf("Expected 4800 tokens remaining after a failed run, got %d", remaining)
+	}
+}
+
+func TestApplyMinimumCaps(t *testing.T) {
+	m := NewManager(NewMemoryStore())
+	m.SetLimit("tenant_a", 600)
+
+	req := &runs.CreateRunRequest{AssistantID: "asst_123", Metadata: types.Metadata{"tenant_id": "tenant_a"}}
+	res, err := m.Apply(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *req.MaxPromptTokens != 344 || *req.MaxCompletionTokens != 256 {
+		t.Errorf("Expected caps 344/256, got %d/%d", *req.MaxPromptTokens, *req.MaxCompletionTokens)
+	}
+	if err := req.Validate(); err != nil {
+		t.Errorf("Expected the clamped request to be valid, got %v", err)
+	}
+	res.Release()
+
+	m.SetLimit("tenant_a", 400)
+	if _, err := m.Apply(req); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Expected an allowance below the minimum caps to be refused, got %v", err)
+	}
+}
+
+func TestRecentSet(t *testing.T) {
+	s := newRecentSet(2)
+	s.add("a")
+	s.add("b")
+	s.remove("a")
+	s.add("a")
+	s.add("c")
+	if s.has("b") || !s.has("a") || !s.has("c") {
+		t.Errorf("Expected only the two most recent IDs, got %v", s.ids)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budget.json")
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := store.Add("tenant_a", 42); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if consumed, _ := reopened.Consumed("tenant_a"); consumed != 42 {
+		t.Errorf("Expected 42 tokens consumed after reopening, got %d", consumed)
+	}
+
+	if err := reopened.Reset("tenant_a"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if consumed, _ := reopened.Consumed("tenant_a"); consumed != 0 {
+		t.Errorf("Expected 0 tokens consumed after reset, got %d", consumed)
+	}
+}
@@ -0,0 +1,143 @@
+package budget
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the number of tokens consumed per key
+type Store interface {
+	// Consumed returns the tokens consumed by key
+	Consumed(key string) (int, error)
+	// Add adds tokens to key and returns the new total
+	Add(key string, tokens int) (int, error)
+	// Reset sets the tokens consumed by key back to zero
+	Reset(key string) error
+}
+
+// MemoryStore is an in-memory Store
+type MemoryStore struct {
+	mu       sync.Mutex
+	consumed map[string]int
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{consumed: make(map[string]int)}
+}
+
+// Consumed returns the tokens consumed by key
+func (s *MemoryStore) Consumed(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.consumed[key], nil
+}
+
+// Add adds tokens to key and returns the new total
+func (s *MemoryStore) Add(key string, tokens int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.consumed == nil {
+		s.consumed = make(map[string]int)
+	}
+	s.consumed[key] += tokens
+	return s.consumed[key], nil
+}
+
+// Reset sets the tokens consumed by key back to zero
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.consumed, key)
+	return nil
+}
+
+// FileStore is a Store kept in a JSON file. Every change is written to disk
+// before it is acknowledged, replacing the file atomically.
+type FileStore struct {
+	path string
+
+	mu       sync.Mutex
+	consumed map[string]int
+}
+
+// NewFileStore opens the store at path, creating it on the first write if it does not exist
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, consumed: make(map[string]int)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.consumed); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Consumed returns the tokens consumed by key
+func (s *FileStore) Consumed(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.consumed[key], nil
+}
+
+// Add adds tokens to key and returns the new total
+func (s *FileStore) Add(key string, tokens int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.consumed[key] += tokens
+	if err := s.save(); err != nil {
+		s.consumed[key] -= tokens
+		return 0, err
+	}
+	return s.consumed[key], nil
+}
+
+// Reset sets the tokens consumed by key back to zero
+func (s *FileStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.consumed[key]
+	delete(s.consumed, key)
+	if err := s.save(); err != nil {
+		if ok {
+			s.consumed[key] = previous
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the store to disk; the caller must hold s.mu
+func (s *FileStore) save() error {
+	data, err := json.MarshalIndent(s.consumed, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,188 @@
+package continuation
+
+import (
+	"context"
+	"maps"
+	"strings"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+const (
+	defaultMaxContinuations = 2
+	defaultInstructions     = "Your previous response was cut off. Continue exactly where it stopped, " +
+		"without repeating any text and without acknowledging the interruption."
+)
+
+// Reply is one logical assistant reply stitched from a run and its continuations
+type Reply struct {
+	// Text is the concatenated text of every assistant message in the reply
+	Text string
+	// Messages are the assistant messages making up the reply, in order
+	Messages []messages.Message
+	// Runs are the original run followed by each continuation run
+	Runs []*runs.Run
+	// Complete is false when the reply is still truncated after the last continuation
+	Complete bool
+}
+
+// Continuer starts follow-up runs for replies truncated by token limits
+type Continuer struct {
+	// MaxContinuations caps the number of follow-up runs per reply. Defaults to 2.
+	MaxContinuations int
+	// Instructions are added to each follow-up run as additional instructions
+	Instructions string
+	// MaxCompletionTokens is the completion budget of each follow-up run.
+	// Zero reuses the budget of the run being continued.
+	MaxCompletionTokens int
+	// Wait drives a follow-up run until it is finished. Defaults to polling until it is terminal or needs action.
+	Wait func(ctx context.Context, run *runs.Run) (*runs.Run, error)
+
+	runs     *runs.Service
+	messages *messages.Service
+}
+
+// New creates a new continuer using the provided client
+func New(c *client.Client) *Continuer {
+	return &Continuer{
+		MaxContinuations: defaultMaxContinuations,
+		Instructions:     defaultInstructions,
+		runs:             runs.New(c),
+		messages:         messages.New(c),
+	}
+}
+
+// RunTruncated reports whether the run stopped because it ran out of completion tokens
+func RunTruncated(run *runs.Run) bool {
+	return run.Status == runs.StatusIncomplete &&
+		run.IncompleteDetails != nil &&
+		run.IncompleteDetails.Reason == "max_completion_tokens"
+}
+
+// MessageTruncated reports whether the message was cut off by a token limit
+func MessageTruncated(msg *messages.Message) bool {
+	return msg.Status == "incomplete" &&
+		msg.IncompleteDetails != nil &&
+		msg.IncompleteDetails.Reason == "max_tokens"
+}
+
+// Text returns the text content of a message
+func Text(msg *messages.Message) string {
+	var b strings.Builder
+	for _, content := range msg.Content {
+		if content.Type == "text" && content.Text != nil {
+			b.WriteString(content.Text.Value)
+		}
+	}
+	return b.String()
+}
+
+// Continue collects the reply of a finished run and, while it is truncated, starts
+// follow-up runs on the same thread until it is complete or the cap is reached
+func (c *Continuer) Continue(ctx context.Context, run *runs.Run) (*Reply, error) {
+	reply := &Reply{}
+
+	for continuation := 0; ; continuation++ {
+		reply.Runs = append(reply.Runs, run)
+
+		msgs, err := c.runMessages(run)
+		if err != nil {
+			return reply, err
+		}
+		reply.Messages = append(reply.Messages, msgs...)
+
+		truncated := RunTruncated(run)
+		if len(msgs) > 0 && MessageTruncated(&msgs[len(msgs)-1]) {
+			truncated = true
+		}
+		if !truncated {
+			reply.Complete = run.Status == runs.StatusCompleted
+			break
+		}
+		if continuation >= c.MaxContinuations {
+			break
+		}
+
+		run, err = c.startContinuation(ctx, run)
+		if err != nil {
+			return reply, err
+		}
+	}
+
+	texts := make([]string, 0, len(reply.Messages))
+	for i := range reply.Messages {
+		texts = append(texts, Text(&reply.Messages[i]))
+	}
+	reply.Text = strings.Join(texts, "")
+
+	return reply, nil
+}
+
+func (c *Continuer) startContinuation(ctx context.Context, previous *runs.Run) (*runs.Run, error) {
+	instructions := c.Instructions
+	if instructions == "" {
+		instructions = defaultInstructions
+	}
+
+	// Continue under the same model, prompt and settings as the output being extended
+	req := &runs.CreateRunRequest{
+		AssistantID:            previous.AssistantID,
+		Instructions:           previous.Instructions,
+		AdditionalInstructions: &instructions,
+		Tools:                  previous.Tools,
+		Temperature:            previous.Temperature,
+		TopP:                   previous.TopP,
+		ResponseFormat:         previous.ResponseFormat,
+		TruncationStrategy:     previous.TruncationStrategy,
+		MaxPromptTokens:        previous.MaxPromptTokens,
+		Metadata:               maps.Clone(previous.Metadata),
+	}
+	if previous.Model != "" {
+		model := previous.Model
+		req.Model = &model
+	}
+	if c.MaxCompletionTokens > 0 {
+		budget := c.MaxCompletionTokens
+		req.MaxCompletionTokens = &budget
+	} else if previous.MaxCompletionTokens != nil {
+		budget := *previous.MaxCompletionTokens
+		req.MaxCompletionTokens = &budget
+	}
+
+	run, err := c.runs.Create(previous.ThreadID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Wait != nil {
+		return c.Wait(ctx, run)
+	}
+	return runs.NewWatcher(c.runs).Watch(ctx, run.ThreadID, run.ID)
+}
+
+// runMessages lists the assistant messages created by a run, oldest first
+func (c *Continuer) runMessages(run *runs.Run) ([]messages.Message, error) {
+	order := "asc"
+	runID := run.ID
+	params := &messages.ListMessagesParams{Order: &order, RunID: &runID}
+
+	var result []messages.Message
+	for {
+		page, err := c.messages.List(run.ThreadID, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range page.Data {
+			if msg.Role == "assistant" {
+				result = append(result, msg)
+			}
+		}
+		if !page.HasMore || page.LastID == "" {
+			return result, nil
+		}
+		after := page.LastID
+		params.After = &after
+	}
+}
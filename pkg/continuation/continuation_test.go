@@ -0,0 +1,140 @@
+package continuation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+func textMessage(id, text string, truncated bool) messages.Message {
+	msg := messages.Message{
+		ID:      id,
+		Role:    "assistant",
+		Status:  "completed",
+		Content: []messages.Content{{Type: "text", Text: &messages.Text{Value: text}}},
+	}
+	if truncated {
+		msg.Status = "incomplete"
+		msg.IncompleteDetails = &messages.IncompleteDetails{Reason: "max_tokens"}
+	}
+	return msg
+}
+
+func TestContinue(t *testing.T) {
+	replies := map[string]messages.Message{
+		"run_1": textMessage("msg_1", "The quick brown ", true),
+		"run_2": textMessage("msg_2", "fox jumps ", true),
+		"run_3": textMessage("msg_3", "over the lazy dog.", false),
+	}
+	var created []runs.CreateRunRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/threads/thread_123/messages":
+			runID := r.URL.Query().Get("run_id")
+			json.NewEncoder(w).Encode(messages.ListMessagesResponse{Data: []messages.Message{replies[runID]}})
+		case "/threads/thread_123/runs":
+			var req runs.CreateRunRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			created = append(created, req)
+			runID := "run_" + string(rune('1'+len(created)))
+			json.NewEncoder(w).Encode(runs.Run{ID: runID, ThreadID: "thread_123", AssistantID: req.AssistantID, Model: *req.Model, Temperature: req.Temperature, Status: runs.StatusQueued})
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	continuer := New(c)
+	continuer.Wait = func(ctx context.Context, run *runs.Run) (*runs.Run, error) {
+		run.Status = runs.StatusCompleted
+		return run, nil
+	}
+
+	budget := 50
+	temperature := 0.2
+	instructions := "Write one sentence."
+	reply, err := continuer.Continue(context.Background(), &runs.Run{
+		ID:                  "run_1",
+		ThreadID:            "thread_123",
+		AssistantID:         "asst_123",
+		Model:               "gpt-4o-mini",
+		Instructions:        &instructions,
+		Temperature:         &temperature,
+		Metadata:            types.Metadata{"tenant_id": "tenant_a"},
+		Status:              runs.StatusIncomplete,
+		IncompleteDetails:   &runs.IncompleteDetails{Reason: "max_completion_tokens"},
+		MaxCompletionTokens: &budget,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if reply.Text != "The quick brown fox jumps over the lazy dog." {
+		t.Errorf("Unexpected stitched text %q", reply.Text)
+	}
+	if !reply.Complete {
+		t.Error("Expected reply to be complete")
+	}
+	if len(reply.Runs) != 3 || len(created) != 2 {
+		t.Fatalf("Expected 2 continuation runs, got %d", len(created))
+	}
+	if created[0].AdditionalInstructions == nil || created[0].MaxCompletionTokens == nil || *created[0].MaxCompletionTokens != 50 {
+		t.Errorf("Expected continuation instructions and budget, got %+v", created[0])
+	}
+	first := created[0]
+	if *first.Model != "gpt-4o-mini" || *first.Instructions != instructions || *first.Temperature != 0.2 || first.Metadata["tenant_id"] != "tenant_a" {
+		t.Errorf("Expected the continuation to keep the original run's settings, got %+v", first)
+	}
+	if *created[1].Model != "gpt-4o-mini" || created[1].Temperature == nil {
+		t.Errorf("Expected later continuations to keep the settings too, got %+v", created[1])
+	}
+}
+
+func TestContinueRespectsCap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			json.NewEncoder(w).Encode(runs.Run{ID: "run_2", ThreadID: "thread_123", Status: runs.StatusQueued})
+			return
+		}
+		json.NewEncoder(w).Encode(messages.ListMessagesResponse{Data: []messages.Message{textMessage("msg", "more ", true)}})
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	continuer := New(c)
+	continuer.MaxContinuations = 1
+	continuer.Wait = func(ctx context.Context, run *runs.Run) (*runs.Run, error) {
+		run.Status = runs.StatusCompleted
+		return run, nil
+	}
+
+	reply, err := continuer.Continue(context.Background(), &runs.Run{ID: "run_1", ThreadID: "thread_123", Status: runs.StatusCompleted})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reply.Complete {
+		t.Error("Expected reply to still be incomplete")
+	}
+	if len(reply.Runs) != 2 {
+		t.Errorf("Expected 1 continuation, got %d", len(reply.Runs)-1)
+	}
+}
@@ -0,0 +1,288 @@
+package coordinator
+
+import (
+	"context"
+	"sync"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+// Locker serializes runs on a thread. Implementations backed by a shared store
+// (a database row, Redis, etc.) let several processes share one coordinator.
+type Locker interface {
+	// Lock blocks until the lock for key is held or ctx is done, and returns the function releasing it
+	Lock(ctx context.Context, key string) (func(), error)
+}
+
+// TryLocker is implemented by lockers that can report a held lock without waiting.
+// Send queues the message instead of blocking when the thread is locked elsewhere,
+// for example by a run started in another process.
+type TryLocker interface {
+	// TryLock acquires the lock for key if it is free and reports whether it did
+	TryLock(ctx context.Context, key string) (func(), bool, error)
+}
+
+// LocalLocker is an in-process Locker
+type LocalLocker struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+// NewLocalLocker creates a new in-process locker
+func NewLocalLocker() *LocalLocker {
+	return &LocalLocker{locks: make(map[string]chan struct{})}
+}
+
+// Lock acquires the lock for key
+func (l *LocalLocker) Lock(ctx context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]chan struct{})
+	}
+	ch, ok := l.locks[key]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		l.locks[key] = ch
+	}
+	l.mu.Unlock()
+
+	select {
+	case ch <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-ch }) }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Coordinator allows one active run per thread and queues messages sent while a run is active
+type Coordinator struct {
+	// Locker guards each thread while a run is active. Defaults to a LocalLocker.
+	Locker Locker
+
+	messages *messages.Service
+	runs     *runs.Service
+
+	mu      sync.Mutex
+	threads map[string]*threadState
+}
+
+type threadState struct {
+	// starts counts the runs started on the thread that are active or waiting for the lock
+	starts int
+	run    *runs.Run
+	unlock func()
+	queue  []*messages.CreateMessageRequest
+}
+
+// New creates a new coordinator using the provided client
+func New(c *client.Client) *Coordinator {
+	return &Coordinator{
+		Locker:   NewLocalLocker(),
+		messages: messages.New(c),
+		runs:     runs.New(c),
+		threads:  make(map[string]*threadState),
+	}
+}
+
+// Send adds a message to the thread. If a run started by this coordinator is active or
+// waiting on the thread, the message is queued and sent before the next run starts;
+// otherwise it is created right away. When the Locker implements TryLocker, messages are
+// also queued while the thread is locked elsewhere. The returned message is nil when the
+// message was queued.
+func (c *Coordinator) Send(ctx context.Context, threadID string, req *messages.CreateMessageRequest) (*messages.Message, error) {
+	c.mu.Lock()
+	if c.busy(threadID) {
+		c.enqueue(threadID, req)
+		c.mu.Unlock()
+		return nil, nil
+	}
+	c.mu.Unlock()
+
+	var unlock func()
+	if tl, ok := c.Locker.(TryLocker); ok {
+		var locked bool
+		var err error
+		unlock, locked, err = tl.TryLock(ctx, threadID)
+		if err != nil {
+			return nil, err
+		}
+		if !locked {
+			c.mu.Lock()
+			c.enqueue(threadID, req)
+			c.mu.Unlock()
+			return nil, nil
+		}
+	} else {
+		var err error
+		unlock, err = c.Locker.Lock(ctx, threadID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer unlock()
+
+	return c.messages.Create(threadID, req)
+}
+
+// Start waits until no other run is active on the thread, sends every queued message and
+// creates the run. The thread stays locked until Finish is called.
+func (c *Coordinator) Start(ctx context.Context, threadID string, req *runs.CreateRunRequest) (*runs.Run, error) {
+	// Mark the thread busy before waiting, so messages sent meanwhile are queued
+	c.mu.Lock()
+	state := c.state(threadID)
+	state.starts++
+	c.mu.Unlock()
+
+	unlock, err := c.Locker.Lock(ctx, threadID)
+	if err != nil {
+		c.mu.Lock()
+		state.starts--
+		c.cleanup(threadID, state)
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	c.mu.Lock()
+	state.unlock = unlock
+	c.mu.Unlock()
+
+	if err := c.flush(threadID); err != nil {
+		c.release(threadID)
+		return nil, err
+	}
+
+	run, err := c.runs.Create(threadID, req)
+	if err != nil {
+		c.release(threadID)
+		return nil, err
+	}
+
+	c.mu.Lock()
+	state.run = run
+	c.mu.Unlock()
+
+	return run, nil
+}
+
+// Finish marks the active run on the thread as done and releases the thread.
+// Messages queued in the meantime are sent by the next Start or by Flush.
+func (c *Coordinator) Finish(threadID string) {
+	c.release(threadID)
+}
+
+// Do starts a run, calls fn to drive it to completion and then finishes it
+func (c *Coordinator) Do(ctx context.Context, threadID string, req *runs.CreateRunRequest, fn func(ctx context.Context, run *runs.Run) error) error {
+	run, err := c.Start(ctx, threadID, req)
+	if err != nil {
+		return err
+	}
+	defer c.Finish(threadID)
+
+	return fn(ctx, run)
+}
+
+// Flush sends the queued messages of a thread that has no active run
+func (c *Coordinator) Flush(ctx context.Context, threadID string) error {
+	unlock, err := c.Locker.Lock(ctx, threadID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return c.flush(threadID)
+}
+
+// Active returns the run currently active on the thread, if any
+func (c *Coordinator) Active(threadID string) *runs.Run {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if state, ok := c.threads[threadID]; ok {
+		return state.run
+	}
+	return nil
+}
+
+// Pending returns the number of messages queued for the thread
+func (c *Coordinator) Pending(threadID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if state, ok := c.threads[threadID]; ok {
+		return len(state.queue)
+	}
+	return 0
+}
+
+// flush sends queued messages in order; the caller must hold the thread lock.
+// On failure the unsent messages stay queued.
+func (c *Coordinator) flush(threadID string) error {
+	for {
+		c.mu.Lock()
+		state := c.state(threadID)
+		if len(state.queue) == 0 {
+			c.cleanup(threadID, state)
+			c.mu.Unlock()
+			return nil
+		}
+		req := state.queue[0]
+		c.mu.Unlock()
+
+		if _, err := c.messages.Create(threadID, req); err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		state.queue = state.queue[1:]
+		c.mu.Unlock()
+	}
+}
+
+func (c *Coordinator) release(threadID string) {
+	c.mu.Lock()
+	state, ok := c.threads[threadID]
+	if !ok || state.unlock == nil {
+		c.mu.Unlock()
+		return
+	}
+	unlock := state.unlock
+	state.starts--
+	state.run = nil
+	state.unlock = nil
+	c.cleanup(threadID, state)
+	c.mu.Unlock()
+
+	unlock()
+}
+
+// busy reports whether a run is active or waiting on the thread; the caller must hold c.mu
+func (c *Coordinator) busy(threadID string) bool {
+	state, ok := c.threads[threadID]
+	return ok && state.starts > 0
+}
+
+// enqueue queues a message for the thread; the caller must hold c.mu
+func (c *Coordinator) enqueue(threadID string, req *messages.CreateMessageRequest) {
+	state := c.state(threadID)
+	state.queue = append(state.queue, req)
+}
+
+// cleanup forgets a thread with no runs and no queued messages; the caller must hold c.mu
+func (c *Coordinator) cleanup(threadID string, state *threadState) {
+	if state.starts == 0 && len(state.queue) == 0 {
+		delete(c.threads, threadID)
+	}
+}
+
+// state returns the state for a thread, creating it if needed; the caller must hold c.mu
+func (c *Coordinator) state(threadID string) *threadState {
+	state, ok := c.threads[threadID]
+	if !ok {
+		state = &threadState{}
+		c.threads[threadID] = state
+	}
+	return state
+}
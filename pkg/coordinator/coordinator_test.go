@@ -0,0 +1,211 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+func newTestServer(t *testing.T, requests *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/messages"):
+			var req messages.CreateMessageRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			*requests = append(*requests, "message:"+req.Content.(string))
+			json.NewEncoder(w).Encode(messages.Message{ID: "msg_123", ThreadID: "thread_123"})
+		case strings.HasSuffix(r.URL.Path, "/runs"):
+			*requests = append(*requests, "run")
+			json.NewEncoder(w).Encode(runs.Run{ID: "run_123", ThreadID: "thread_123", Status: runs.StatusQueued})
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestSendQueuesWhileRunActive(t *testing.T) {
+	var requests []string
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+	coord := New(c)
+	ctx := context.Background()
+
+	if msg, err := coord.Send(ctx, "thread_123", &messages.CreateMessageRequest{Role: "user", Content: "first"}); err != nil || msg == nil {
+		t.Fatalf("Expected message to be created, got %v, %v", msg, err)
+	}
+
+	run, err := coord.Start(ctx, "thread_123", &runs.CreateRunRequest{AssistantID: "asst_123"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if coord.Active("thread_123") != run {
+		t.Error("Expected run to be active")
+	}
+
+	msg, err := coord.Send(ctx, "thread_123", &messages.CreateMessageRequest{Role: "user", Content: "second"})
+	if err != nil || msg != nil {
+		t.Fatalf("Expected message to be queued, got %v, %v", msg, err)
+	}
+	if coord.Pending("thread_123") != 1 {
+		t.Errorf("Expected 1 pending message, got %d", coord.Pending("thread_123"))
+	}
+
+	coord.Finish("thread_123")
+
+	if _, err := coord.Start(ctx, "thread_123", &runs.CreateRunRequest{AssistantID: "asst_123"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	coord.Finish("thread_123")
+
+	expected := []string{"message:first", "run", "message:second", "run"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected requests %v, got %v", expected, requests)
+	}
+	if coord.Pending("thread_123") != 0 {
+		t.Errorf("Expected no pending messages, got %d", coord.Pending("thread_123"))
+	}
+}
+
+func TestStartWaitsForActiveRun(t *testing.T) {
+	var requests []string
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+	coord := New(c)
+
+	if _, err := coord.Start(context.Background(), "thread_123", &runs.CreateRunRequest{AssistantID: "asst_123"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := coord.Start(ctx, "thread_123", &runs.CreateRunRequest{AssistantID: "asst_123"}); err != context.DeadlineExceeded {
+		t.Errorf("Expected second run to wait for the first, got %v", err)
+	}
+
+	coord.Finish("thread_123")
+
+	err := coord.Do(context.Background(), "thread_123", &runs.CreateRunRequest{AssistantID: "asst_123"}, func(ctx context.Context, run *runs.Run) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if coord.Active("thread_123") != nil {
+		t.Error("Expected no active run after Do")
+	}
+}
+
+// tryLocker adds TryLock to a LocalLocker, like a locker backed by a shared store
+type tryLocker struct {
+	*LocalLocker
+}
+
+func (l tryLocker) TryLock(ctx context.Context, key string) (func(), bool, error) {
+	l.mu.Lock()
+	ch, ok := l.locks[key]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		l.locks[key] = ch
+	}
+	l.mu.Unlock()
+
+	select {
+	case ch <- struct{}{}:
+		return func() { <-ch }, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+func TestSendQueuesWhileLockedElsewhere(t *testing.T) {
+	var requests []string
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+	coord := New(c)
+	ctx := context.Background()
+
+	// Another process holds the thread
+	unlock, err := coord.Locker.Lock(ctx, "thread_123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A run waiting for the lock marks the thread busy
+	started := make(chan error)
+	go func() {
+		_, err := coord.Start(ctx, "thread_123", &runs.CreateRunRequest{AssistantID: "asst_123"})
+		started <- err
+	}()
+	for {
+		coord.mu.Lock()
+		waiting := coord.busy("thread_123")
+		coord.mu.Unlock()
+		if waiting {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	msg, err := coord.Send(ctx, "thread_123", &messages.CreateMessageRequest{Role: "user", Content: "waiting"})
+	if err != nil || msg != nil {
+		t.Fatalf("Expected message to be queued while the run waits, got %v, %v", msg, err)
+	}
+
+	unlock()
+	if err := <-started; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	coord.Finish("thread_123")
+
+	// With a TryLocker, Send queues instead of blocking while the thread is locked elsewhere
+	coord.Locker = tryLocker{NewLocalLocker()}
+	unlock, err = coord.Locker.Lock(ctx, "thread_123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	msg, err = coord.Send(ctx, "thread_123", &messages.CreateMessageRequest{Role: "user", Content: "locked"})
+	if err != nil || msg != nil {
+		t.Fatalf("Expected message to be queued while locked elsewhere, got %v, %v", msg, err)
+	}
+	unlock()
+
+	if err := coord.Flush(ctx, "thread_123"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"message:waiting", "run", "message:locked"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected requests %v, got %v", expected, requests)
+	}
+}
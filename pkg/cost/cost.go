@@ -0,0 +1,139 @@
+package cost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runsteps"
+)
+
+// ErrUnknownModel is returned when the price table has no entry for a model
+var ErrUnknownModel = errors.New("no price configured for model")
+
+// Price is the price of a model in currency units per one million tokens
+type Price struct {
+	Input       float64 `json:"input"`
+	CachedInput float64 `json:"cached_input"`
+	Output      float64 `json:"output"`
+}
+
+// PriceTable maps model names to prices. A model without an exact entry uses the
+// entry with the longest matching prefix ending at a "-", so "gpt-4o" also prices
+// "gpt-4o-2024-08-06" but "gpt-4" does not price "gpt-4o" or "gpt-4.1".
+type PriceTable map[string]Price
+
+// LoadPriceTable reads a price table from JSON
+func LoadPriceTable(r io.Reader) (PriceTable, error) {
+	var table PriceTable
+	if err := json.NewDecoder(r).Decode(&table); err != nil {
+		return nil, fmt.Errorf("failed to decode price table: %w", err)
+	}
+	return table, nil
+}
+
+// LoadPriceTableFile reads a price table from a JSON file
+func LoadPriceTableFile(path string) (PriceTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadPriceTable(f)
+}
+
+// Lookup returns the price for a model
+func (t PriceTable) Lookup(model string) (Price, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+
+	var best string
+	for name := range t {
+		// Exact matches are handled above, so a family must be followed by a "-"
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// Tokens is the token usage being priced
+type Tokens struct {
+	Input       int `json:"input_tokens"`
+	CachedInput int `json:"cached_input_tokens"`
+	Output      int `json:"output_tokens"`
+}
+
+// Cost is the price of a token usage, broken down by token kind
+type Cost struct {
+	Input       float64 `json:"input"`
+	CachedInput float64 `json:"cached_input"`
+	Output      float64 `json:"output"`
+	Total       float64 `json:"total"`
+}
+
+// Compute prices the token usage of a model. Cached input tokens are part of the
+// input tokens and are charged at the cached rate instead of the input rate.
+func (t PriceTable) Compute(model string, tokens Tokens) (Cost, error) {
+	price, ok := t.Lookup(model)
+	if !ok {
+		return Cost{}, fmt.Errorf("%w: %s", ErrUnknownModel, model)
+	}
+
+	uncached := tokens.Input - tokens.CachedInput
+	if uncached < 0 {
+		uncached = 0
+	}
+
+	c := Cost{
+		Input:       float64(uncached) * price.Input / 1e6,
+		CachedInput: float64(tokens.CachedInput) * price.CachedInput / 1e6,
+		Output:      float64(tokens.Output) * price.Output / 1e6,
+	}
+	c.Total = c.Input + c.CachedInput + c.Output
+	return c, nil
+}
+
+// RunTokens extracts the token usage of a run
+func RunTokens(run *runs.Run) Tokens {
+	if run.Usage == nil {
+		return Tokens{}
+	}
+	tokens := Tokens{Input: run.Usage.PromptTokens, Output: run.Usage.CompletionTokens}
+	if run.Usage.PromptTokensDetails != nil {
+		tokens.CachedInput = run.Usage.PromptTokensDetails.CachedTokens
+	}
+	return tokens
+}
+
+// StepTokens extracts the token usage of a run step
+func StepTokens(step *runsteps.RunStep) Tokens {
+	if step.Usage == nil {
+		return Tokens{}
+	}
+	tokens := Tokens{Input: step.Usage.PromptTokens, Output: step.Usage.CompletionTokens}
+	if step.Usage.PromptTokensDetails != nil {
+		tokens.CachedInput = step.Usage.PromptTokensDetails.CachedTokens
+	}
+	return tokens
+}
+
+// RunCost computes the cost of a run from its usage
+func (t PriceTable) RunCost(run *runs.Run) (Cost, error) {
+	return t.Compute(run.Model, RunTokens(run))
+}
+
+// StepCost computes the cost of a run step. Steps do not report their model,
+// so the model of the run the step belongs to must be given.
+func (t PriceTable) StepCost(model string, step *runsteps.RunStep) (Cost, error) {
+	return t.Compute(model, StepTokens(step))
+}
@@ -0,0 +1,148 @@
+package cost
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runsteps"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func testPrices() PriceTable {
+	return PriceTable{
+		"gpt-4o":      {Input: 2.5, CachedInput: 1.25, Output: 10},
+		"gpt-4o-mini": {Input: 0.15, CachedInput: 0.075, Output: 0.6},
+	}
+}
+
+func TestLookup(t *testing.T) {
+	prices := testPrices()
+
+	tests := map[string]float64{
+		"gpt-4o":                 2.5,
+		"gpt-4o-2024-08-06":      2.5,
+		"gpt-4o-mini-2024-07-18": 0.15,
+	}
+	for model, input := range tests {
+		price, ok := prices.Lookup(model)
+		if !ok || price.Input != input {
+			t.Errorf("%s: expected input price %v, got %v (found %v)", model, input, price.Input, ok)
+		}
+	}
+
+	if _, err := prices.Compute("gpt-3.5-turbo", Tokens{Input: 1}); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("Expected ErrUnknownModel, got %v", err)
+	}
+
+	gpt4 := PriceTable{"gpt-4": {Input: 30, Output: 60}}
+	for _, model := range []string{"gpt-4o-mini", "gpt-4.1", "gpt-4turbo"} {
+		if _, err := gpt4.Compute(model, Tokens{Input: 1}); !errors.Is(err, ErrUnknownModel) {
+			t.Errorf("%s: expected ErrUnknownModel, got %v", model, err)
+		}
+	}
+	if price, ok := gpt4.Lookup("gpt-4-0613"); !ok || price.Input != 30 {
+		t.Errorf("Expected gpt-4-0613 to use the gpt-4 price, got %v (found %v)", price, ok)
+	}
+}
+
+func TestRunCost(t *testing.T) {
+	run := &runs.Run{
+		ID:    "run_123",
+		Model: "gpt-4o",
+		Usage: &runs.Usage{
+			PromptTokens:        1000000,
+			CompletionTokens:    200000,
+			TotalTokens:         1200000,
+			PromptTokensDetails: &runs.PromptTokensDetails{CachedTokens: 400000},
+		},
+	}
+
+	c, err := testPrices().RunCost(run)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !almostEqual(c.Input, 1.5) || !almostEqual(c.CachedInput, 0.5) || !almostEqual(c.Output, 2) || !almostEqual(c.Total, 4) {
+		t.Errorf("Unexpected cost %+v", c)
+	}
+}
+
+func TestLedger(t *testing.T) {
+	ledger := NewLedger(testPrices())
+
+	add := func(id, assistant, thread, customer string, prompt, completion int) {
+		_, err := ledger.AddRun(&runs.Run{
+			ID:          id,
+			AssistantID: assistant,
+			ThreadID:    thread,
+			Model:       "gpt-4o-mini",
+			Metadata:    types.Metadata{"customer_id": customer},
+			Usage:       &runs.Usage{PromptTokens: prompt, CompletionTokens: completion},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	add("run_1", "asst_1", "thread_1", "cus_a", 1000000, 0)
+	add("run_2", "asst_1", "thread_2", "cus_b", 0, 1000000)
+	add("run_3", "asst_2", "thread_2", "cus_a", 1000000, 1000000)
+
+	byCustomer := ledger.ByMetadata("customer_id")
+	if len(byCustomer) != 2 {
+		t.Fatalf("Expected 2 customers, got %d", len(byCustomer))
+	}
+	if byCustomer[0].Key != "cus_a" || byCustomer[0].Entries != 2 || !almostEqual(byCustomer[0].Cost.Total, 0.9) {
+		t.Errorf("Unexpected total for cus_a: %+v", byCustomer[0])
+	}
+
+	byAssistant := ledger.ByAssistant()
+	if len(byAssistant) != 2 || !almostEqual(byAssistant[0].Cost.Total, 0.75) {
+		t.Errorf("Unexpected totals by assistant: %+v", byAssistant)
+	}
+
+	if total := ledger.Total(); total.Entries != 3 || !almostEqual(total.Cost.Total, 1.5) {
+		t.Errorf("Unexpected overall total: %+v", total)
+	}
+
+	step := &runsteps.RunStep{
+		ID:          "step_1",
+		RunID:       "run_4",
+		AssistantID: "asst_2",
+		ThreadID:    "thread_3",
+		Usage:       &runsteps.Usage{PromptTokens: 1000000},
+	}
+	entry, err := ledger.AddStep(&runs.Run{ID: "run_4", Model: "gpt-4o"}, step)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if entry.StepID != "step_1" || !almostEqual(entry.Cost.Total, 2.5) {
+		t.Errorf("Unexpected step entry: %+v", entry)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, byCustomer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "cus_a,2,2000000,0,1000000,") {
+		t.Errorf("Unexpected CSV output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, byCustomer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var decoded []Total
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 2 {
+		t.Errorf("Expected JSON totals to round trip, got %v (%v)", decoded, err)
+	}
+}
@@ -0,0 +1,216 @@
+package cost
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runsteps"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// Entry is a priced run or run step recorded in a ledger
+type Entry struct {
+	RunID       string         `json:"run_id"`
+	StepID      string         `json:"step_id,omitempty"`
+	AssistantID string         `json:"assistant_id"`
+	ThreadID    string         `json:"thread_id"`
+	Model       string         `json:"model"`
+	Metadata    types.Metadata `json:"metadata,omitempty"`
+	Tokens      Tokens         `json:"tokens"`
+	Cost        Cost           `json:"cost"`
+}
+
+// Total is the aggregated usage and cost of a group of entries
+type Total struct {
+	Key     string `json:"key"`
+	Entries int    `json:"entries"`
+	Tokens  Tokens `json:"tokens"`
+	Cost    Cost   `json:"cost"`
+}
+
+// Ledger records the cost of runs and run steps and aggregates them for chargeback
+type Ledger struct {
+	prices PriceTable
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewLedger creates a new ledger pricing usage with the given table
+func NewLedger(prices PriceTable) *Ledger {
+	return &Ledger{prices: prices}
+}
+
+// AddRun prices a run and records it
+func (l *Ledger) AddRun(run *runs.Run) (Entry, error) {
+	tokens := RunTokens(run)
+	c, err := l.prices.Compute(run.Model, tokens)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		RunID:       run.ID,
+		AssistantID: run.AssistantID,
+		ThreadID:    run.ThreadID,
+		Model:       run.Model,
+		Metadata:    run.Metadata,
+		Tokens:      tokens,
+		Cost:        c,
+	}
+	l.Add(entry)
+	return entry, nil
+}
+
+// AddStep prices a run step and records it. The run supplies the model and metadata.
+// Record either a run or its steps, not both, or the usage is counted twice.
+func (l *Ledger) AddStep(run *runs.Run, step *runsteps.RunStep) (Entry, error) {
+	tokens := StepTokens(step)
+	c, err := l.prices.Compute(run.Model, tokens)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		RunID:       step.RunID,
+		StepID:      step.ID,
+		AssistantID: step.AssistantID,
+		ThreadID:    step.ThreadID,
+		Model:       run.Model,
+		Metadata:    run.Metadata,
+		Tokens:      tokens,
+		Cost:        c,
+	}
+	l.Add(entry)
+	return entry, nil
+}
+
+// Add records an already priced entry
+func (l *Ledger) Add(entry Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+// Entries returns a copy of the recorded entries
+func (l *Ledger) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Entry(nil), l.entries...)
+}
+
+// Total returns the total of every recorded entry
+func (l *Ledger) Total() Total {
+	totals := l.GroupBy(func(Entry) string { return "total" })
+	if len(totals) == 0 {
+		return Total{Key: "total"}
+	}
+	return totals[0]
+}
+
+// ByAssistant aggregates the entries by assistant ID
+func (l *Ledger) ByAssistant() []Total {
+	return l.GroupBy(func(e Entry) string { return e.AssistantID })
+}
+
+// ByThread aggregates the entries by thread ID
+func (l *Ledger) ByThread() []Total {
+	return l.GroupBy(func(e Entry) string { return e.ThreadID })
+}
+
+// ByModel aggregates the entries by model
+func (l *Ledger) ByModel() []Total {
+	return l.GroupBy(func(e Entry) string { return e.Model })
+}
+
+// ByMetadata aggregates the entries by the value of a metadata key, such as a customer ID.
+// Entries without the key are grouped under an empty key.
+func (l *Ledger) ByMetadata(key string) []Total {
+	return l.GroupBy(func(e Entry) string {
+		if v, ok := e.Metadata[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	})
+}
+
+// GroupBy aggregates the entries by an arbitrary key, sorted by key
+func (l *Ledger) GroupBy(key func(Entry) string) []Total {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	groups := make(map[string]*Total)
+	for _, e := range l.entries {
+		k := key(e)
+		t, ok := groups[k]
+		if !ok {
+			t = &Total{Key: k}
+			groups[k] = t
+		}
+		t.Entries++
+		t.Tokens.Input += e.Tokens.Input
+		t.Tokens.CachedInput += e.Tokens.CachedInput
+		t.Tokens.Output += e.Tokens.Output
+		t.Cost.Input += e.Cost.Input
+		t.Cost.CachedInput += e.Cost.CachedInput
+		t.Cost.Output += e.Cost.Output
+		t.Cost.Total += e.Cost.Total
+	}
+
+	totals := make([]Total, 0, len(groups))
+	for _, t := range groups {
+		totals = append(totals, *t)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Key < totals[j].Key })
+	return totals
+}
+
+// WriteJSON writes totals as a JSON array
+func WriteJSON(w io.Writer, totals []Total) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(totals)
+}
+
+// WriteCSV writes totals as CSV with a header row
+func WriteCSV(w io.Writer, totals []Total) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		"key", "entries",
+		"input_tokens", "cached_input_tokens", "output_tokens",
+		"input_cost", "cached_input_cost", "output_cost", "total_cost",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, t := range totals {
+		record := []string{
+			t.Key,
+			strconv.Itoa(t.Entries),
+			strconv.Itoa(t.Tokens.Input),
+			strconv.Itoa(t.Tokens.CachedInput),
+			strconv.Itoa(t.Tokens.Output),
+			formatAmount(t.Cost.Input),
+			formatAmount(t.Cost.CachedInput),
+			formatAmount(t.Cost.Output),
+			formatAmount(t.Cost.Total),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
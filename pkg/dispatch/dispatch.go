@@ -0,0 +1,191 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+// ErrNoToolCalls is returned by Submit when the run is not waiting on tool outputs
+var ErrNoToolCalls = errors.New("run does not require tool outputs")
+
+// Handler executes a single function tool call and returns the output for the model
+type Handler func(ctx context.Context, call runs.ToolCall) (string, error)
+
+// ErrorFormatter converts a failed tool call into the output sent back to the model
+type ErrorFormatter func(call runs.ToolCall, err error) string
+
+// Dispatcher runs the tool calls of a run concurrently and collects their outputs
+type Dispatcher struct {
+	// MaxWorkers limits how many tool calls run at once. Zero or less means no limit.
+	MaxWorkers int
+	// ToolTimeout is the default time limit for a single tool call. Zero means no limit.
+	ToolTimeout time.Duration
+	// Timeout is the time limit for executing all tool calls of a run. Zero means no limit.
+	Timeout time.Duration
+	// FormatError builds the output for failed calls. Defaults to a JSON error object.
+	FormatError ErrorFormatter
+
+	mu       sync.RWMutex
+	handlers map[string]registration
+}
+
+type registration struct {
+	handler Handler
+	timeout time.Duration
+}
+
+// PanicError is reported for a tool call whose handler panicked
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("tool handler panicked: %v", e.Value)
+}
+
+// New creates a new dispatcher with no registered handlers
+func New() *Dispatcher {
+	return &Dispatcher{handlers: make(map[string]registration)}
+}
+
+// Register registers the handler for the function with the given name
+func (d *Dispatcher) Register(name string, h Handler) {
+	d.RegisterWithTimeout(name, h, 0)
+}
+
+// RegisterWithTimeout registers a handler with its own time limit, overriding ToolTimeout
+func (d *Dispatcher) RegisterWithTimeout(name string, h Handler, timeout time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.handlers == nil {
+		d.handlers = make(map[string]registration)
+	}
+	d.handlers[name] = registration{handler: h, timeout: timeout}
+}
+
+// Execute runs every tool call and returns one output per call, in the same order.
+// Failures, timeouts and panics are reported to the model as error outputs.
+func (d *Dispatcher) Execute(ctx context.Context, calls []runs.ToolCall) []runs.ToolOutput {
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	outputs := make([]runs.ToolOutput, len(calls))
+
+	workers := d.MaxWorkers
+	if workers <= 0 || workers > len(calls) {
+		workers = len(calls)
+	}
+	sem := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		outputs[i].ToolCallID = call.ID
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			outputs[i].Output = d.formatError(call, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, call runs.ToolCall) {
+			defer wg.Done()
+
+			// The slot is released when the handler exits, which may be after the call timed out
+			output, err := d.call(ctx, call, func() { <-sem })
+			if err != nil {
+				output = d.formatError(call, err)
+			}
+			outputs[i].Output = output
+		}(i, call)
+	}
+	wg.Wait()
+
+	return outputs
+}
+
+// Submit executes the tool calls a run is waiting on and submits all outputs in a single request
+func (d *Dispatcher) Submit(ctx context.Context, service *runs.Service, run *runs.Run) (*runs.Run, error) {
+	if run.RequiredAction == nil || run.RequiredAction.SubmitToolOutputs == nil {
+		return nil, ErrNoToolCalls
+	}
+
+	outputs := d.Execute(ctx, run.RequiredAction.SubmitToolOutputs.ToolCalls)
+
+	return service.SubmitToolOutputs(run.ThreadID, run.ID, &runs.SubmitToolOutputsRequest{
+		ToolOutputs: outputs,
+	})
+}
+
+// call runs the handler for a single tool call, enforcing its timeout and recovering panics.
+// release is called once no handler is running for the call.
+func (d *Dispatcher) call(ctx context.Context, call runs.ToolCall, release func()) (string, error) {
+	if call.Function == nil {
+		release()
+		return "", fmt.Errorf("unsupported tool call type: %s", call.Type)
+	}
+
+	d.mu.RLock()
+	reg, ok := d.handlers[call.Function.Name]
+	d.mu.RUnlock()
+	if !ok {
+		release()
+		return "", fmt.Errorf("no handler registered for function: %s", call.Function.Name)
+	}
+
+	timeout := reg.timeout
+	if timeout <= 0 {
+		timeout = d.ToolTimeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		output string
+		err    error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		defer release()
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: &PanicError{Value: r}}
+			}
+		}()
+		output, err := reg.handler(ctx, call)
+		done <- result{output: output, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.output, res.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("tool call %s (%s) did not finish: %w", call.ID, call.Function.Name, ctx.Err())
+	}
+}
+
+func (d *Dispatcher) formatError(call runs.ToolCall, err error) string {
+	if d.FormatError != nil {
+		return d.FormatError(call, err)
+	}
+
+	output, marshalErr := json.Marshal(map[string]string{"error": err.Error()})
+	if marshalErr != nil {
+		return err.Error()
+	}
+	return string(output)
+}
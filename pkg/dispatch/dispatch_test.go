@@ -0,0 +1,189 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+func functionCall(id, name string) runs.ToolCall {
+	return runs.ToolCall{
+		ID:       id,
+		Type:     "function",
+		Function: &runs.FunctionCall{Name: name, Arguments: "{}"},
+	}
+}
+
+func TestExecute(t *testing.T) {
+	d := New()
+	d.Register("ok", func(ctx context.Context, call runs.ToolCall) (string, error) {
+		return "result for " + call.ID, nil
+	})
+	d.Register("fail", func(ctx context.Context, call runs.ToolCall) (string, error) {
+		return "", errors.New("boom")
+	})
+	d.Register("panic", func(ctx context.Context, call runs.ToolCall) (string, error) {
+		panic("unexpected")
+	})
+	d.RegisterWithTimeout("slow", func(ctx context.Context, call runs.ToolCall) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}, 10*time.Millisecond)
+
+	calls := []runs.ToolCall{
+		functionCall("call_1", "ok"),
+		functionCall("call_2", "fail"),
+		functionCall("call_3", "panic"),
+		functionCall("call_4", "slow"),
+		functionCall("call_5", "missing"),
+	}
+
+	outputs := d.Execute(context.Background(), calls)
+	if len(outputs) != len(calls) {
+		t.Fatalf("Expected %d outputs, got %d", len(calls), len(outputs))
+	}
+
+	for i, output := range outputs {
+		if output.ToolCallID != calls[i].ID {
+			t.Errorf("Expected output %d for %s, got %s", i, calls[i].ID, output.ToolCallID)
+		}
+	}
+
+	if outputs[0].Output != "result for call_1" {
+		t.Errorf("Unexpected output for call_1: %s", outputs[0].Output)
+	}
+
+	expected := map[int]string{1: "boom", 2: "panicked", 3: "deadline exceeded", 4: "no handler"}
+	for i, fragment := range expected {
+		var body map[string]string
+		if err := json.Unmarshal([]byte(outputs[i].Output), &body); err != nil {
+			t.Fatalf("Expected JSON error output for %s, got %s", calls[i].ID, outputs[i].Output)
+		}
+		if !strings.Contains(body["error"], fragment) {
+			t.Errorf("Expected error for %s to contain %q, got %q", calls[i].ID, fragment, body["error"])
+		}
+	}
+}
+
+func TestExecuteMaxWorkers(t *testing.T) {
+	var running, peak int32
+
+	d := New()
+	d.MaxWorkers = 2
+	d.Register("work", func(ctx context.Context, call runs.ToolCall) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return "done", nil
+	})
+
+	var calls []runs.ToolCall
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		calls = append(calls, functionCall(id, "work"))
+	}
+
+	d.Execute(context.Background(), calls)
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent calls, got %d", peak)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/threads/thread_123/runs/run_123/submit_tool_outputs" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+
+		var req runs.SubmitToolOutputsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if len(req.ToolOutputs) != 2 {
+			t.Errorf("Expected 2 tool outputs in one request, got %d", len(req.ToolOutputs))
+		}
+
+		json.NewEncoder(w).Encode(runs.Run{ID: "run_123", ThreadID: "thread_123", Status: "queued"})
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	d := New()
+	d.Register("echo", func(ctx context.Context, call runs.ToolCall) (string, error) {
+		return call.Function.Arguments, nil
+	})
+
+	run := &runs.Run{
+		ID:       "run_123",
+		ThreadID: "thread_123",
+		Status:   "requires_action",
+		RequiredAction: &runs.RequiredAction{
+			Type: "submit_tool_outputs",
+			SubmitToolOutputs: &runs.SubmitToolOutputs{
+				ToolCalls: []runs.ToolCall{functionCall("call_1", "echo"), functionCall("call_2", "echo")},
+			},
+		},
+	}
+
+	updated, err := d.Submit(context.Background(), runs.New(c), run)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Status != "queued" {
+		t.Errorf("Expected status queued, got %s", updated.Status)
+	}
+
+	if _, err := d.Submit(context.Background(), runs.New(c), &runs.Run{ID: "run_456"}); !errors.Is(err, ErrNoToolCalls) {
+		t.Errorf("Expected ErrNoToolCalls, got %v", err)
+	}
+}
+
+func TestExecuteMaxWorkersAfterTimeout(t *testing.T) {
+	var running, peak int32
+
+	d := New()
+	d.MaxWorkers = 1
+	d.ToolTimeout = 5 * time.Millisecond
+	d.Register("stuck", func(ctx context.Context, call runs.ToolCall) (string, error) {
+		// Ignores the context and keeps running past its timeout
+		n := atomic.AddInt32(&running, 1)
+		if n > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, n)
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return "done", nil
+	})
+
+	calls := []runs.ToolCall{functionCall("a", "stuck"), functionCall("b", "stuck"), functionCall("c", "stuck")}
+	outputs := d.Execute(context.Background(), calls)
+
+	for _, output := range outputs {
+		if !strings.Contains(output.Output, "deadline exceeded") {
+			t.Errorf("Expected %s to time out, got %s", output.ToolCallID, output.Output)
+		}
+	}
+	if p := atomic.LoadInt32(&peak); p > 1 {
+		t.Errorf("Expected timed out handlers to keep their slot, got %d concurrent handlers", p)
+	}
+}
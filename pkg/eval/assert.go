@@ -0,0 +1,106 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runsteps"
+)
+
+// Output is what a case produced: the assistant's reply, the run and its steps
+type Output struct {
+	Text  string
+	Run   *runs.Run
+	Steps []runsteps.RunStep
+}
+
+// AssertionResult is the outcome of a single assertion
+type AssertionResult struct {
+	Type    string `json:"type"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// Evaluate checks a single assertion against the output of a case
+func Evaluate(a Assertion, out *Output) AssertionResult {
+	result := AssertionResult{Type: a.Type}
+	if err := evaluate(a, out); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	result.Passed = true
+	return result
+}
+
+func evaluate(a Assertion, out *Output) error {
+	switch a.Type {
+	case AssertContains, AssertNotContains:
+		text, value := out.Text, a.Value
+		if a.IgnoreCase {
+			text, value = strings.ToLower(text), strings.ToLower(value)
+		}
+		found := strings.Contains(text, value)
+		if a.Type == AssertContains && !found {
+			return fmt.Errorf("response does not contain %q", a.Value)
+		}
+		if a.Type == AssertNotContains && found {
+			return fmt.Errorf("response contains %q", a.Value)
+		}
+		return nil
+
+	case AssertRegex:
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		if !re.MatchString(out.Text) {
+			return fmt.Errorf("response does not match %q", a.Value)
+		}
+		return nil
+
+	case AssertJSONSchema:
+		var doc interface{}
+		if err := json.Unmarshal([]byte(stripCodeFence(out.Text)), &doc); err != nil {
+			return fmt.Errorf("response is not valid JSON: %w", err)
+		}
+		return ValidateSchema(a.Schema, doc)
+
+	case AssertToolCalled:
+		for _, step := range out.Steps {
+			for _, call := range step.StepDetails.ToolCalls {
+				if call.Type == a.Value || (call.Type == "function" && call.Function.Name == a.Value) {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("tool %q was not called", a.Value)
+
+	case AssertMaxTokens:
+		if out.Run == nil || out.Run.Usage == nil {
+			return fmt.Errorf("run has no usage")
+		}
+		if out.Run.Usage.CompletionTokens > a.Max {
+			return fmt.Errorf("run used %d completion tokens, limit is %d", out.Run.Usage.CompletionTokens, a.Max)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown assertion type: %s", a.Type)
+	}
+}
+
+// stripCodeFence removes a surrounding markdown code fence, which models often add to JSON replies
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
@@ -0,0 +1,143 @@
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// Assertion types supported by Evaluate
+const (
+	AssertContains    = "contains"
+	AssertNotContains = "not_contains"
+	AssertRegex       = "regex"
+	AssertJSONSchema  = "json_schema"
+	AssertToolCalled  = "tool_called"
+	AssertMaxTokens   = "max_tokens"
+)
+
+// Case is a single evaluation case run on a fresh thread
+type Case struct {
+	Name         string         `json:"name" yaml:"name"`
+	Messages     []Message      `json:"messages" yaml:"messages"`
+	Instructions string         `json:"instructions,omitempty" yaml:"instructions,omitempty"` // overrides the assistant's instructions
+	Metadata     types.Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Assertions   []Assertion    `json:"assertions" yaml:"assertions"`
+}
+
+// Message is an input message of a case
+type Message struct {
+	Role        string       `json:"role" yaml:"role"`
+	Content     string       `json:"content" yaml:"content"`
+	Attachments []Attachment `json:"attachments,omitempty" yaml:"attachments,omitempty"`
+}
+
+// Attachment is a file attached to an input message, with the tools that may use it
+type Attachment struct {
+	FileID string   `json:"file_id" yaml:"file_id"`
+	Tools  []string `json:"tools,omitempty" yaml:"tools,omitempty"`
+}
+
+// Assertion is a check applied to the result of a case
+type Assertion struct {
+	// Type is one of the Assert* constants
+	Type string `json:"type" yaml:"type"`
+	// Value is the substring, pattern or tool name the assertion checks for
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+	// IgnoreCase makes contains and not_contains case-insensitive
+	IgnoreCase bool `json:"ignore_case,omitempty" yaml:"ignore_case,omitempty"`
+	// Schema is the JSON schema the response must satisfy for json_schema
+	Schema map[string]interface{} `json:"schema,omitempty" yaml:"schema,omitempty"`
+	// Max is the completion token limit for max_tokens
+	Max int `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// LoadDataset reads cases from a file. Files ending in .yaml or .yml hold a YAML list of
+// cases, .jsonl files hold one JSON case per line and .json files hold a JSON array.
+func LoadDataset(path string) ([]Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return DecodeYAML(f)
+	case ".jsonl":
+		return DecodeJSONL(f)
+	case ".json":
+		var cases []Case
+		if err := json.NewDecoder(f).Decode(&cases); err != nil {
+			return nil, fmt.Errorf("failed to decode dataset: %w", err)
+		}
+		return cases, nil
+	default:
+		return nil, fmt.Errorf("unsupported dataset format: %s", path)
+	}
+}
+
+// DecodeYAML reads a YAML list of cases
+func DecodeYAML(r io.Reader) ([]Case, error) {
+	var cases []Case
+	if err := yaml.NewDecoder(r).Decode(&cases); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to decode dataset: %w", err)
+	}
+	return cases, nil
+}
+
+// DecodeJSONL reads one JSON case per line, skipping blank lines
+func DecodeJSONL(r io.Reader) ([]Case, error) {
+	var cases []Case
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var c Case
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("failed to decode dataset line %d: %w", line, err)
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// threadMessages converts the input messages of a case for thread creation
+func (c *Case) threadMessages() []runs.Message {
+	msgs := make([]runs.Message, 0, len(c.Messages))
+	for _, m := range c.Messages {
+		role := m.Role
+		if role == "" {
+			role = "user"
+		}
+		msg := runs.Message{Role: role, Content: m.Content}
+		for _, a := range m.Attachments {
+			attachment := messages.Attachment{FileID: a.FileID}
+			for _, tool := range a.Tools {
+				attachment.Tools = append(attachment.Tools, messages.Tool{Type: tool})
+			}
+			msg.Attachments = append(msg.Attachments, attachment)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
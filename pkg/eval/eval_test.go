@@ -0,0 +1,274 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runsteps"
+)
+
+const yamlDataset = `
+- name: capital
+  messages:
+    - role: user
+      content: What is the capital of France?
+      attachments:
+        - file_id: file_123
+          tools: [file_search]
+  assertions:
+    - type: contains
+      value: paris
+      ignore_case: true
+    - type: json_schema
+      schema:
+        type: object
+        required: [city]
+        properties:
+          city: {type: string, enum: [Paris]}
+`
+
+func TestLoadDataset(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "cases.yaml")
+	jsonlPath := filepath.Join(dir, "cases.jsonl")
+	os.WriteFile(yamlPath, []byte(yamlDataset), 0o644)
+	os.WriteFile(jsonlPath, []byte(`{"name":"a","messages":[{"role":"user","content":"hi"}],"assertions":[{"type":"max_tokens","max":10}]}
+
+{"name":"b","messages":[{"role":"user","content":"bye"}]}
+`), 0o644)
+
+	cases, err := LoadDataset(yamlPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cases) != 1 || cases[0].Name != "capital" {
+		t.Fatalf("Expected one case named capital, got %+v", cases)
+	}
+	if len(cases[0].Messages[0].Attachments) != 1 || cases[0].Messages[0].Attachments[0].Tools[0] != "file_search" {
+		t.Errorf("Expected attachment with file_search, got %+v", cases[0].Messages[0].Attachments)
+	}
+	if !cases[0].Assertions[0].IgnoreCase || cases[0].Assertions[1].Schema["type"] != "object" {
+		t.Errorf("Unexpected assertions: %+v", cases[0].Assertions)
+	}
+
+	cases, err = LoadDataset(jsonlPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cases) != 2 || cases[0].Assertions[0].Max != 10 {
+		t.Errorf("Expected two cases, got %+v", cases)
+	}
+
+	if _, err := LoadDataset(filepath.Join(dir, "cases.csv")); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	out := &Output{
+		Text: "```json\n{\"city\": \"Paris\", \"population\": 2100000}\n```",
+		Run:  &runs.Run{Usage: &runs.Usage{CompletionTokens: 20}},
+		Steps: []runsteps.RunStep{{StepDetails: runsteps.StepDetails{ToolCalls: []runsteps.ToolCall{
+			{Type: "function", Function: runsteps.Function{Name: "lookup_city"}},
+			{Type: "file_search"},
+		}}}},
+	}
+
+	tests := []struct {
+		assertion Assertion
+		passed    bool
+	}{
+		{Assertion{Type: AssertContains, Value: "Paris"}, true},
+		{Assertion{Type: AssertContains, Value: "paris"}, false},
+		{Assertion{Type: AssertContains, Value: "paris", IgnoreCase: true}, true},
+		{Assertion{Type: AssertNotContains, Value: "London"}, true},
+		{Assertion{Type: AssertRegex, Value: `"population": \d+`}, true},
+		{Assertion{Type: AssertRegex, Value: `^Paris$`}, false},
+		{Assertion{Type: AssertToolCalled, Value: "lookup_city"}, true},
+		{Assertion{Type: AssertToolCalled, Value: "file_search"}, true},
+		{Assertion{Type: AssertToolCalled, Value: "code_interpreter"}, false},
+		{Assertion{Type: AssertMaxTokens, Max: 20}, true},
+		{Assertion{Type: AssertMaxTokens, Max: 19}, false},
+		{Assertion{Type: AssertJSONSchema, Schema: map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"city", "population"},
+			"properties": map[string]interface{}{
+				"city":       map[string]interface{}{"type": "string"},
+				"population": map[string]interface{}{"type": "integer", "minimum": 1},
+			},
+			"additionalProperties": false,
+		}}, true},
+		{Assertion{Type: AssertJSONSchema, Schema: map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"country"},
+		}}, false},
+		{Assertion{Type: "unknown"}, false},
+	}
+
+	for _, tt := range tests {
+		result := Evaluate(tt.assertion, out)
+		if result.Passed != tt.passed {
+			t.Errorf("Expected %s %q to pass=%v, got %+v", tt.assertion.Type, tt.assertion.Value, tt.passed, result)
+		}
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string", "pattern": "^[a-z]+$"},
+				map[string]interface{}{"type": "null"},
+			},
+		},
+		"maxItems": 2,
+	}
+
+	var doc interface{}
+	json.Unmarshal([]byte(`["abc", null]`), &doc)
+	if err := ValidateSchema(schema, doc); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	json.Unmarshal([]byte(`["abc", "ABC"]`), &doc)
+	err := ValidateSchema(schema, doc)
+	if err == nil || !strings.Contains(err.Error(), "$[1]") {
+		t.Errorf("Expected error at $[1], got %v", err)
+	}
+
+	json.Unmarshal([]byte(`["a", "b", "c"]`), &doc)
+	if err := ValidateSchema(schema, doc); err == nil {
+		t.Error("Expected error for too many items")
+	}
+}
+
+func TestRunner(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/threads/runs":
+			var req runs.CreateThreadAndRunRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.AssistantID != "asst_123" || len(req.Thread.Messages) != 1 {
+				t.Errorf("Unexpected request: %+v", req)
+			}
+			json.NewEncoder(w).Encode(runs.Run{ID: "run_123", ThreadID: "thread_123", Status: runs.StatusQueued})
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			w.Write([]byte(`{"id":"thread_123","deleted":true}`))
+		case strings.HasSuffix(r.URL.Path, "/messages"):
+			json.NewEncoder(w).Encode(messages.ListMessagesResponse{Data: []messages.Message{{
+				Role:    "assistant",
+				Content: []messages.Content{{Type: "text", Text: &messages.Text{Value: "The capital is Paris."}}},
+			}}})
+		case strings.HasSuffix(r.URL.Path, "/steps"):
+			json.NewEncoder(w).Encode(runsteps.ListRunStepsResponse{})
+		default:
+			json.NewEncoder(w).Encode(runs.Run{
+				ID: "run_123", ThreadID: "thread_123", Status: runs.StatusCompleted,
+				Usage: &runs.Usage{CompletionTokens: 12},
+			})
+		}
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+	runner := New(c, "asst_123")
+	runner.runs.PollInterval = time.Millisecond
+
+	cases := []Case{
+		{
+			Name:       "passes",
+			Messages:   []Message{{Role: "user", Content: "Capital of France?"}},
+			Assertions: []Assertion{{Type: AssertContains, Value: "Paris"}, {Type: AssertMaxTokens, Max: 50}},
+		},
+		{
+			Name:       "fails",
+			Messages:   []Message{{Content: "Capital of France?"}},
+			Assertions: []Assertion{{Type: AssertToolCalled, Value: "lookup_city"}},
+		},
+	}
+
+	report, err := runner.Run(context.Background(), cases)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Passed != 1 || report.Failed != 1 || report.Errors != 0 {
+		t.Errorf("Expected 1 passed and 1 failed, got %+v", report)
+	}
+	if report.Results[0].Response != "The capital is Paris." || report.Results[0].RunID != "run_123" {
+		t.Errorf("Unexpected result: %+v", report.Results[0])
+	}
+	if len(deleted) != 2 {
+		t.Errorf("Expected both threads to be deleted, got %v", deleted)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJUnit(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	xml := buf.String()
+	if !strings.Contains(xml, `tests="2" failures="1" errors="0"`) || !strings.Contains(xml, `<failure message="tool_called: tool &#34;lookup_city&#34; was not called">`) {
+		t.Errorf("Unexpected JUnit report: %s", xml)
+	}
+
+	// A failed result without a failed assertion, as in a report built by hand
+	manual := &Report{Failed: 1, Results: []CaseResult{{Name: "manual"}}}
+	buf.Reset()
+	if err := manual.WriteJUnit(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), `<failure message="case failed">`) {
+		t.Errorf("Expected a generic failure message, got %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Results) != 2 {
+		t.Errorf("Expected JSON report to round-trip, got %v", err)
+	}
+}
+
+func TestRunnerRunFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			json.NewEncoder(w).Encode(runs.Run{ID: "run_123", ThreadID: "thread_123", Status: runs.StatusQueued})
+		case "DELETE":
+			w.Write([]byte(`{"deleted":true}`))
+		default:
+			json.NewEncoder(w).Encode(runs.Run{
+				ID: "run_123", ThreadID: "thread_123", Status: runs.StatusFailed,
+				LastError: &runs.ErrorObject{Code: "server_error", Message: "boom"},
+			})
+		}
+	}))
+	defer server.Close()
+
+	runner := New(&client.Client{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()}, "asst_123")
+	runner.runs.PollInterval = time.Millisecond
+
+	result := runner.RunCase(context.Background(), Case{Name: "broken", Messages: []Message{{Content: "hi"}}})
+	if result.Passed || !strings.Contains(result.Error, "server_error") || result.Status != "failed" || result.Duration == 0 {
+		t.Errorf("Expected failed run to be reported as an error, got %+v", result)
+	}
+}
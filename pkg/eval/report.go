@@ -0,0 +1,140 @@
+package eval
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+// CaseResult is the outcome of a single case
+type CaseResult struct {
+	Name       string            `json:"name"`
+	ThreadID   string            `json:"thread_id,omitempty"`
+	RunID      string            `json:"run_id,omitempty"`
+	Status     string            `json:"status,omitempty"`
+	Passed     bool              `json:"passed"`
+	Error      string            `json:"error,omitempty"`
+	Response   string            `json:"response,omitempty"`
+	Usage      *runs.Usage       `json:"usage,omitempty"`
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+	Duration   time.Duration     `json:"duration_ns"`
+}
+
+// Report is the outcome of an evaluation run
+type Report struct {
+	AssistantID string        `json:"assistant_id"`
+	StartedAt   time.Time     `json:"started_at"`
+	Duration    time.Duration `json:"duration_ns"`
+	Passed      int           `json:"passed"`
+	Failed      int           `json:"failed"`
+	Errors      int           `json:"errors"`
+	Results     []CaseResult  `json:"results"`
+}
+
+func (r *Report) finish() {
+	r.Duration = time.Since(r.StartedAt)
+	r.Passed, r.Failed, r.Errors = 0, 0, 0
+	for _, res := range r.Results {
+		switch {
+		case res.Error != "":
+			r.Errors++
+		case res.Passed:
+			r.Passed++
+		default:
+			r.Failed++
+		}
+	}
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML with one test case per evaluation case
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitSuite{
+		Name:      "eval." + r.AssistantID,
+		Tests:     len(r.Results),
+		Failures:  r.Failed,
+		Errors:    r.Errors,
+		Time:      seconds(r.Duration),
+		Timestamp: r.StartedAt.UTC().Format(time.RFC3339),
+	}
+	for _, res := range r.Results {
+		tc := junitCase{
+			Name:      res.Name,
+			ClassName: suite.Name,
+			Time:      seconds(res.Duration),
+			SystemOut: res.Response,
+		}
+		switch {
+		case res.Error != "":
+			tc.Error = &junitMessage{Message: res.Error}
+		case !res.Passed:
+			var lines []string
+			for _, a := range res.Assertions {
+				if !a.Passed {
+					lines = append(lines, fmt.Sprintf("%s: %s", a.Type, a.Message))
+				}
+			}
+			// Results built by hand or decoded from JSON may fail without a failed assertion
+			if len(lines) == 0 {
+				lines = []string{"case failed"}
+			}
+			tc.Failure = &junitMessage{Message: lines[0], Body: strings.Join(lines, "\n")}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
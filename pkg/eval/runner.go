@@ -0,0 +1,205 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runsteps"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/threads"
+)
+
+// Runner runs evaluation cases against an assistant
+type Runner struct {
+	// AssistantID is the assistant under test
+	AssistantID string
+	// CaseTimeout bounds each case. Zero means no limit beyond the context passed to Run.
+	CaseTimeout time.Duration
+	// OnAction resolves runs that need action, such as function tool calls.
+	// When nil, a run that needs action is cancelled and the case fails.
+	OnAction runs.ActionHandler
+	// KeepThreads leaves the per-case threads in place instead of deleting them
+	KeepThreads bool
+
+	runs     *runs.Service
+	messages *messages.Service
+	steps    *runsteps.Service
+	threads  *threads.Service
+}
+
+// New creates a new runner for the assistant using the provided client
+func New(c *client.Client, assistantID string) *Runner {
+	return &Runner{
+		AssistantID: assistantID,
+		runs:        runs.New(c),
+		messages:    messages.New(c),
+		steps:       runsteps.New(c),
+		threads:     threads.New(c),
+	}
+}
+
+// Run runs every case on a fresh thread and returns the report.
+// It only returns an error when ctx is done; case failures are recorded in the report.
+func (r *Runner) Run(ctx context.Context, cases []Case) (*Report, error) {
+	report := &Report{AssistantID: r.AssistantID, StartedAt: time.Now()}
+	for _, c := range cases {
+		if err := ctx.Err(); err != nil {
+			report.finish()
+			return report, err
+		}
+		report.Results = append(report.Results, r.RunCase(ctx, c))
+	}
+	report.finish()
+	return report, nil
+}
+
+// RunCase runs a single case on a fresh thread and evaluates its assertions
+func (r *Runner) RunCase(ctx context.Context, c Case) (result CaseResult) {
+	start := time.Now()
+	result.Name = c.Name
+	defer func() { result.Duration = time.Since(start) }()
+
+	if r.CaseTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.CaseTimeout)
+		defer cancel()
+	}
+
+	out, err := r.execute(ctx, c, &result)
+	if result.ThreadID != "" && !r.KeepThreads {
+		r.threads.Delete(result.ThreadID)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Response = out.Text
+	result.Usage = out.Run.Usage
+
+	result.Passed = true
+	for _, a := range c.Assertions {
+		ar := Evaluate(a, out)
+		if !ar.Passed {
+			result.Passed = false
+		}
+		result.Assertions = append(result.Assertions, ar)
+	}
+	return result
+}
+
+func (r *Runner) execute(ctx context.Context, c Case, result *CaseResult) (*Output, error) {
+	req := &runs.CreateThreadAndRunRequest{
+		AssistantID: r.AssistantID,
+		Thread:      &runs.ThreadRequest{Messages: c.threadMessages()},
+	}
+	if c.Instructions != "" {
+		instructions := c.Instructions
+		req.Instructions = &instructions
+	}
+	if len(c.Metadata) > 0 {
+		req.Metadata = c.Metadata
+	}
+
+	run, err := r.runs.CreateThreadAndRun(req)
+	if err != nil {
+		return nil, err
+	}
+	result.ThreadID = run.ThreadID
+	result.RunID = run.ID
+
+	watcher := runs.NewWatcher(r.runs)
+	for {
+		run, err = watcher.Watch(ctx, run.ThreadID, run.ID)
+		if err != nil {
+			if run != nil {
+				r.runs.Cancel(run.ThreadID, run.ID)
+			}
+			return nil, err
+		}
+		result.Status = string(run.Status)
+		if run.Status.IsTerminal() {
+			break
+		}
+		if r.OnAction == nil {
+			r.runs.Cancel(run.ThreadID, run.ID)
+			return nil, fmt.Errorf("run requires action but no handler is configured")
+		}
+		updated, err := r.OnAction(ctx, run)
+		if err != nil {
+			return nil, err
+		}
+		if updated != nil {
+			run = updated
+		}
+	}
+
+	if run.Status != runs.StatusCompleted {
+		if run.LastError != nil {
+			return nil, fmt.Errorf("run %s: %s: %s", run.Status, run.LastError.Code, run.LastError.Message)
+		}
+		return nil, fmt.Errorf("run ended with status %s", run.Status)
+	}
+
+	out := &Output{Run: run}
+	if out.Text, err = r.responseText(run); err != nil {
+		return nil, err
+	}
+	if out.Steps, err = r.runSteps(run); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// responseText joins the text of the assistant messages created by the run, oldest first
+func (r *Runner) responseText(run *runs.Run) (string, error) {
+	order := "asc"
+	params := &messages.ListMessagesParams{RunID: &run.ID, Order: &order}
+	var parts []string
+	for {
+		resp, err := r.messages.List(run.ThreadID, params)
+		if err != nil {
+			return "", err
+		}
+		for _, m := range resp.Data {
+			if m.Role != "assistant" {
+				continue
+			}
+			for _, content := range m.Content {
+				if content.Type == "text" && content.Text != nil {
+					parts = append(parts, content.Text.Value)
+				}
+			}
+		}
+		if !resp.HasMore || resp.LastID == "" {
+			break
+		}
+		after := resp.LastID
+		params.After = &after
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// runSteps lists every step of the run
+func (r *Runner) runSteps(run *runs.Run) ([]runsteps.RunStep, error) {
+	order := "asc"
+	params := &runsteps.ListRunStepsParams{Order: &order}
+	var steps []runsteps.RunStep
+	for {
+		resp, err := r.steps.List(run.ThreadID, run.ID, params)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, resp.Data...)
+		if !resp.HasMore || resp.LastID == "" {
+			break
+		}
+		after := resp.LastID
+		params.After = &after
+	}
+	return steps, nil
+}
@@ -0,0 +1,224 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ValidateSchema checks a decoded JSON document against a JSON schema. It supports the
+// subset of keywords used for structured outputs: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength, pattern, minimum,
+// maximum, anyOf, oneOf and allOf. Other keywords are ignored.
+func ValidateSchema(schema map[string]interface{}, doc interface{}) error {
+	return validate(schema, doc, "$")
+}
+
+func validate(schema map[string]interface{}, v interface{}, path string) error {
+	if schema == nil {
+		return nil
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, v) {
+		return fmt.Errorf("%s: expected type %v, got %s", path, t, jsonType(v))
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if equalJSON(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value is not one of %v", path, enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !equalJSON(c, v) {
+		return fmt.Errorf("%s: value must be %v", path, c)
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if err := validateObject(schema, val, path); err != nil {
+			return err
+		}
+	case []interface{}:
+		if n, ok := number(schema["minItems"]); ok && float64(len(val)) < n {
+			return fmt.Errorf("%s: expected at least %v items", path, n)
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(val)) > n {
+			return fmt.Errorf("%s: expected at most %v items", path, n)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range val {
+				if err := validate(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := float64(len([]rune(val)))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			return fmt.Errorf("%s: expected at least %v characters", path, n)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			return fmt.Errorf("%s: expected at most %v characters", path, n)
+		}
+		if p, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern: %w", path, err)
+			}
+			if !re.MatchString(val) {
+				return fmt.Errorf("%s: value does not match %q", path, p)
+			}
+		}
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && val < n {
+			return fmt.Errorf("%s: value must be >= %v", path, n)
+		}
+		if n, ok := number(schema["maximum"]); ok && val > n {
+			return fmt.Errorf("%s: value must be <= %v", path, n)
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, s := range all {
+			if err := validate(asSchema(s), v, path); err != nil {
+				return err
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		if countMatches(anyOf, v, path) == 0 {
+			return fmt.Errorf("%s: value matches none of anyOf", path)
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if n := countMatches(oneOf, v, path); n != 1 {
+			return fmt.Errorf("%s: value matches %d of oneOf, expected exactly one", path, n)
+		}
+	}
+
+	return nil
+}
+
+func validateObject(schema map[string]interface{}, obj map[string]interface{}, path string) error {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, value := range obj {
+		if prop, ok := properties[name]; ok {
+			if err := validate(asSchema(prop), value, path+"."+name); err != nil {
+				return err
+			}
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				return fmt.Errorf("%s: unexpected property %q", path, name)
+			}
+		case map[string]interface{}:
+			if err := validate(extra, value, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func countMatches(schemas []interface{}, v interface{}, path string) int {
+	n := 0
+	for _, s := range schemas {
+		if validate(asSchema(s), v, path) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func matchesType(t interface{}, v interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		return typeIs(t, v)
+	case []interface{}:
+		for _, name := range t {
+			if s, ok := name.(string); ok && typeIs(s, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func typeIs(name string, v interface{}) bool {
+	actual := jsonType(v)
+	if name == "number" && actual == "integer" {
+		return true
+	}
+	return name == actual
+}
+
+func jsonType(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case int:
+		return "integer"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return strings.ToLower(reflect.TypeOf(v).Kind().String())
+}
+
+// asSchema converts a sub-schema, accepting the map types produced by both JSON and YAML decoding
+func asSchema(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	return nil
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// equalJSON compares values that may come from different decoders, such as ints from
+// YAML schemas and float64s from JSON responses
+func equalJSON(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,180 @@
+package experiment
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// Metadata keys used to tag runs with their experiment and variant
+const (
+	MetadataExperiment = "experiment"
+	MetadataVariant    = "experiment_variant"
+)
+
+// Variant is one arm of an experiment. Fields left empty keep the value from the request.
+type Variant struct {
+	Name string `json:"name"`
+	// Weight is the relative share of units assigned to the variant
+	Weight int `json:"weight"`
+
+	AssistantID  string   `json:"assistant_id,omitempty"`
+	Model        *string  `json:"model,omitempty"`
+	Instructions *string  `json:"instructions,omitempty"`
+	Temperature  *float64 `json:"temperature,omitempty"`
+}
+
+// Experiment assigns units, such as users, to variants. Assignment is a hash of the
+// experiment name and the unit key, so a unit always gets the same variant as long as
+// the variants and their weights do not change.
+type Experiment struct {
+	Name     string    `json:"name"`
+	Variants []Variant `json:"variants"`
+}
+
+// Validate checks that the experiment has a name and uniquely named variants with
+// non-negative weights that do not all equal zero
+func (e *Experiment) Validate() error {
+	if e.Name == "" {
+		return errors.New("experiment name is required")
+	}
+	if len(e.Variants) == 0 {
+		return fmt.Errorf("experiment %s has no variants", e.Name)
+	}
+
+	total := 0
+	seen := make(map[string]bool)
+	for _, v := range e.Variants {
+		if v.Name == "" {
+			return fmt.Errorf("experiment %s has a variant without a name", e.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("experiment %s has duplicate variant %s", e.Name, v.Name)
+		}
+		seen[v.Name] = true
+		if v.Weight < 0 {
+			return fmt.Errorf("variant %s has negative weight", v.Name)
+		}
+		total += v.Weight
+	}
+	if total == 0 {
+		return fmt.Errorf("experiment %s has no weight", e.Name)
+	}
+	return nil
+}
+
+// Assign returns the variant for a unit key
+func (e *Experiment) Assign(unitKey string) (*Variant, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+
+	sum := sha256.Sum256([]byte(e.Name + "\x00" + unitKey))
+	bucket := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for i := range e.Variants {
+		bucket -= e.Variants[i].Weight
+		if bucket < 0 {
+			return &e.Variants[i], nil
+		}
+	}
+	// unreachable: bucket is always below the total weight
+	return &e.Variants[len(e.Variants)-1], nil
+}
+
+// Apply assigns the unit to a variant, applies the variant's overrides to the run request
+// and tags the run's metadata with the experiment and variant. It returns an error, leaving
+// the request unchanged, when the tags would exceed the metadata key limit.
+func (e *Experiment) Apply(unitKey string, req *runs.CreateRunRequest) (*Variant, error) {
+	return e.apply(unitKey, runFields{
+		assistantID:  &req.AssistantID,
+		model:        &req.Model,
+		instructions: &req.Instructions,
+		temperature:  &req.Temperature,
+		metadata:     &req.Metadata,
+	})
+}
+
+// ApplyThreadAndRun is Apply for requests that create a thread and run in one call
+func (e *Experiment) ApplyThreadAndRun(unitKey string, req *runs.CreateThreadAndRunRequest) (*Variant, error) {
+	return e.apply(unitKey, runFields{
+		assistantID:  &req.AssistantID,
+		model:        &req.Model,
+		instructions: &req.Instructions,
+		temperature:  &req.Temperature,
+		metadata:     &req.Metadata,
+	})
+}
+
+// runFields points at the fields of a run request that variants override
+type runFields struct {
+	assistantID  *string
+	model        **string
+	instructions **string
+	temperature  **float64
+	metadata     *types.Metadata
+}
+
+// apply assigns the unit to a variant and applies its overrides and tags to the request fields
+func (e *Experiment) apply(unitKey string, f runFields) (*Variant, error) {
+	v, err := e.Assign(unitKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Untagged runs would be left out of the metrics, so the request is refused rather than sent without tags
+	keys := len(*f.metadata)
+	for _, k := range []string{MetadataExperiment, MetadataVariant} {
+		if _, ok := (*f.metadata)[k]; !ok {
+			keys++
+		}
+	}
+	if keys > types.MaxMetadataKeys {
+		return nil, fmt.Errorf("experiment %s: tagging the run needs %d metadata keys, more than the limit of %d", e.Name, keys, types.MaxMetadataKeys)
+	}
+
+	if v.AssistantID != "" {
+		*f.assistantID = v.AssistantID
+	}
+	if v.Model != nil {
+		*f.model = v.Model
+	}
+	if v.Instructions != nil {
+		*f.instructions = v.Instructions
+	}
+	if v.Temperature != nil {
+		*f.temperature = v.Temperature
+	}
+	*f.metadata = e.tag(*f.metadata, v)
+	return v, nil
+}
+
+// tag returns a copy of metadata with the experiment and variant keys set,
+// leaving the caller's map untouched
+func (e *Experiment) tag(metadata types.Metadata, v *Variant) types.Metadata {
+	tagged := make(types.Metadata, len(metadata)+2)
+	for k, val := range metadata {
+		tagged[k] = val
+	}
+	tagged[MetadataExperiment] = e.Name
+	tagged[MetadataVariant] = v.Name
+	return tagged
+}
+
+// VariantOf returns the experiment and variant a run was tagged with
+func VariantOf(run *runs.Run) (experiment, variant string, ok bool) {
+	if run == nil {
+		return "", "", false
+	}
+	experiment, _ = run.Metadata[MetadataExperiment].(string)
+	variant, _ = run.Metadata[MetadataVariant].(string)
+	return experiment, variant, experiment != "" && variant != ""
+}
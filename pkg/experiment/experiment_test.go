@@ -0,0 +1,181 @@
+package experiment
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runsteps"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestAssign(t *testing.T) {
+	e := &Experiment{
+		Name: "prompt-v2",
+		Variants: []Variant{
+			{Name: "control", Weight: 3},
+			{Name: "treatment", Weight: 1},
+			{Name: "off", Weight: 0},
+		},
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		key := fmt.Sprintf("user_%d", i)
+		v, err := e.Assign(key)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		again, _ := e.Assign(key)
+		if again.Name != v.Name {
+			t.Fatalf("Expected stable assignment for %s, got %s and %s", key, v.Name, again.Name)
+		}
+		counts[v.Name]++
+	}
+
+	if counts["off"] != 0 {
+		t.Errorf("Expected zero-weight variant to get no units, got %d", counts["off"])
+	}
+	if counts["control"] < 2800 || counts["control"] > 3200 {
+		t.Errorf("Expected about 3000 control units, got %d", counts["control"])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []Experiment{
+		{Variants: []Variant{{Name: "a", Weight: 1}}},
+		{Name: "x"},
+		{Name: "x", Variants: []Variant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}},
+		{Name: "x", Variants: []Variant{{Name: "a", Weight: -1}, {Name: "b", Weight: 2}}},
+		{Name: "x", Variants: []Variant{{Name: "a"}}},
+	}
+	for i, e := range tests {
+		if _, err := e.Assign("user"); err == nil {
+			t.Errorf("Expected error for experiment %d", i)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	e := &Experiment{
+		Name: "model-swap",
+		Variants: []Variant{{
+			Name:        "mini",
+			Weight:      1,
+			AssistantID: "asst_mini",
+			Model:       ptr("gpt-4o-mini"),
+			Temperature: ptr(0.2),
+		}},
+	}
+
+	original := types.Metadata{"customer": "acme"}
+	req := &runs.CreateRunRequest{AssistantID: "asst_123", Instructions: ptr("keep"), Metadata: original}
+	v, err := e.Apply("user_1", req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v.Name != "mini" || req.AssistantID != "asst_mini" || *req.Model != "gpt-4o-mini" || *req.Temperature != 0.2 {
+		t.Errorf("Expected variant overrides to be applied, got %+v", req)
+	}
+	if *req.Instructions != "keep" {
+		t.Errorf("Expected instructions to be kept, got %s", *req.Instructions)
+	}
+	if req.Metadata[MetadataExperiment] != "model-swap" || req.Metadata[MetadataVariant] != "mini" || req.Metadata["customer"] != "acme" {
+		t.Errorf("Expected metadata to be tagged, got %v", req.Metadata)
+	}
+	if _, ok := original[MetadataExperiment]; ok {
+		t.Error("Expected caller's metadata to be left untouched")
+	}
+
+	threadReq := &runs.CreateThreadAndRunRequest{AssistantID: "asst_123"}
+	if _, err := e.ApplyThreadAndRun("user_1", threadReq); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if threadReq.AssistantID != "asst_mini" || *threadReq.Model != "gpt-4o-mini" || *threadReq.Temperature != 0.2 || threadReq.Metadata[MetadataVariant] != "mini" {
+		t.Errorf("Expected the same overrides on thread-and-run requests, got %+v", threadReq)
+	}
+
+	full := make(types.Metadata, types.MaxMetadataKeys-1)
+	for i := 0; i < types.MaxMetadataKeys-1; i++ {
+		full[fmt.Sprintf("key_%d", i)] = "value"
+	}
+	req = &runs.CreateRunRequest{AssistantID: "asst_123", Metadata: full}
+	if _, err := e.Apply("user_1", req); err == nil {
+		t.Error("Expected an error when the tags exceed the metadata key limit")
+	}
+	if req.AssistantID != "asst_123" || len(req.Metadata) != types.MaxMetadataKeys-1 {
+		t.Errorf("Expected the request to be left unchanged, got %+v", req)
+	}
+
+	// Keys the request already has do not count twice
+	delete(full, "key_0")
+	full[MetadataExperiment] = "model-swap"
+	req = &runs.CreateRunRequest{AssistantID: "asst_123", Metadata: full}
+	if _, err := e.Apply("user_1", req); err != nil {
+		t.Errorf("Expected an existing experiment key to be replaced, got %v", err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	tagged := func(id, variant string, status runs.RunStatus, end int64, tokens int) *runs.Run {
+		run := &runs.Run{
+			ID:        id,
+			Status:    status,
+			CreatedAt: 100,
+			Metadata:  types.Metadata{MetadataExperiment: "exp", MetadataVariant: variant},
+			Usage:     &runs.Usage{TotalTokens: tokens},
+		}
+		if status == runs.StatusCompleted {
+			run.CompletedAt = &end
+		} else {
+			run.FailedAt = &end
+		}
+		return run
+	}
+
+	a1 := tagged("run_1", "a", runs.StatusCompleted, 104, 100)
+	a2 := tagged("run_2", "a", runs.StatusFailed, 102, 50)
+	b1 := tagged("run_3", "b", runs.StatusCompleted, 110, 300)
+
+	for _, run := range []*runs.Run{a1, a2, b1, a1} {
+		m.RecordRun(run)
+	}
+	if m.RecordRun(&runs.Run{ID: "run_4", Status: runs.StatusCompleted}) {
+		t.Error("Expected untagged run to be ignored")
+	}
+	if m.RecordRun(&runs.Run{ID: "run_5", Status: runs.StatusInProgress, Metadata: a1.Metadata}) {
+		t.Error("Expected active run to be ignored")
+	}
+
+	m.RecordSteps(b1, []runsteps.RunStep{
+		{Type: "tool_calls", Status: "failed"},
+		{Type: "tool_calls", Status: "completed"},
+		{Type: "message_creation", Status: "failed"},
+	})
+	m.RecordToolError(a1)
+	m.RecordFeedback(a1, 1)
+	m.RecordFeedback(a2, 0)
+
+	stats := m.Stats("exp")
+	if len(stats) != 2 || stats[0].Variant != "a" || stats[1].Variant != "b" {
+		t.Fatalf("Expected stats for a and b, got %+v", stats)
+	}
+
+	a, b := stats[0], stats[1]
+	if a.Runs != 2 || a.Completed != 1 || a.Failed != 1 || a.SuccessRate() != 0.5 {
+		t.Errorf("Unexpected run counts for a: %+v", a)
+	}
+	if a.AvgLatency() != 3*time.Second || a.AvgTokens() != 75 {
+		t.Errorf("Expected 3s latency and 75 tokens, got %v and %v", a.AvgLatency(), a.AvgTokens())
+	}
+	if a.ToolErrors != 1 || a.AvgFeedback() != 0.5 {
+		t.Errorf("Expected 1 tool error and 0.5 feedback, got %+v", a)
+	}
+	if b.ToolErrors != 1 || b.AvgLatency() != 10*time.Second || b.FeedbackCount != 0 {
+		t.Errorf("Unexpected stats for b: %+v", b)
+	}
+}
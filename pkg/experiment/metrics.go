@@ -0,0 +1,210 @@
+package experiment
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runsteps"
+)
+
+// Stats aggregates outcome metrics for one variant of an experiment
+type Stats struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+
+	Runs      int `json:"runs"`
+	Completed int `json:"completed"`
+	// Failed counts runs that ended failed, expired, cancelled or incomplete
+	Failed int `json:"failed"`
+
+	// Latency is the summed time from creation to the terminal status of the
+	// Timed runs, the runs that reported when they ended
+	Latency time.Duration `json:"latency_ns"`
+	Timed   int           `json:"timed"`
+
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+
+	ToolErrors int `json:"tool_errors"`
+
+	FeedbackCount int     `json:"feedback_count"`
+	FeedbackSum   float64 `json:"feedback_sum"`
+}
+
+// AvgLatency returns the mean run latency
+func (s Stats) AvgLatency() time.Duration {
+	if s.Timed == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(s.Timed)
+}
+
+// AvgTokens returns the mean total tokens per run
+func (s Stats) AvgTokens() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.TotalTokens) / float64(s.Runs)
+}
+
+// SuccessRate returns the share of runs that completed
+func (s Stats) SuccessRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Completed) / float64(s.Runs)
+}
+
+// AvgFeedback returns the mean feedback score
+func (s Stats) AvgFeedback() float64 {
+	if s.FeedbackCount == 0 {
+		return 0
+	}
+	return s.FeedbackSum / float64(s.FeedbackCount)
+}
+
+type variantKey struct {
+	experiment string
+	variant    string
+}
+
+// Metrics aggregates run outcomes per experiment variant. It is safe for concurrent use.
+type Metrics struct {
+	mu       sync.Mutex
+	stats    map[variantKey]*Stats
+	recorded map[string]bool
+}
+
+// NewMetrics creates an empty metrics aggregator
+func NewMetrics() *Metrics {
+	return &Metrics{
+		stats:    make(map[variantKey]*Stats),
+		recorded: make(map[string]bool),
+	}
+}
+
+// RecordRun adds a terminal run to the stats of the variant it was tagged with.
+// Runs that are not tagged or not terminal are ignored, and each run is counted once.
+// It reports whether the run was recorded.
+func (m *Metrics) RecordRun(run *runs.Run) bool {
+	experiment, variant, ok := VariantOf(run)
+	if !ok || !run.Status.IsTerminal() {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.recorded[run.ID] {
+		return false
+	}
+	m.recorded[run.ID] = true
+
+	s := m.get(experiment, variant)
+	s.Runs++
+	if run.Status == runs.StatusCompleted {
+		s.Completed++
+	} else {
+		s.Failed++
+	}
+	if end := endedAt(run); end > 0 && end >= run.CreatedAt {
+		s.Latency += time.Duration(end-run.CreatedAt) * time.Second
+		s.Timed++
+	}
+	if run.Usage != nil {
+		s.PromptTokens += run.Usage.PromptTokens
+		s.CompletionTokens += run.Usage.CompletionTokens
+		s.TotalTokens += run.Usage.TotalTokens
+	}
+	return true
+}
+
+// RecordSteps counts failed tool call steps of a run against its variant
+func (m *Metrics) RecordSteps(run *runs.Run, steps []runsteps.RunStep) {
+	experiment, variant, ok := VariantOf(run)
+	if !ok {
+		return
+	}
+
+	failed := 0
+	for _, step := range steps {
+		if step.Type == "tool_calls" && step.Status == "failed" {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(experiment, variant).ToolErrors += failed
+}
+
+// RecordToolError counts a tool error detected by the application, such as a function
+// tool whose handler returned an error
+func (m *Metrics) RecordToolError(run *runs.Run) {
+	experiment, variant, ok := VariantOf(run)
+	if !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(experiment, variant).ToolErrors++
+}
+
+// RecordFeedback adds a user feedback score, such as 1 for thumbs up and 0 for thumbs down,
+// to the variant the run was tagged with
+func (m *Metrics) RecordFeedback(run *runs.Run, score float64) {
+	experiment, variant, ok := VariantOf(run)
+	if !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.get(experiment, variant)
+	s.FeedbackCount++
+	s.FeedbackSum += score
+}
+
+// Stats returns the stats of every variant of an experiment, sorted by variant name
+func (m *Metrics) Stats(experiment string) []Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []Stats
+	for key, s := range m.stats {
+		if key.experiment == experiment {
+			result = append(result, *s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Variant < result[j].Variant })
+	return result
+}
+
+func (m *Metrics) get(experiment, variant string) *Stats {
+	key := variantKey{experiment, variant}
+	s, ok := m.stats[key]
+	if !ok {
+		s = &Stats{Experiment: experiment, Variant: variant}
+		m.stats[key] = s
+	}
+	return s
+}
+
+func endedAt(run *runs.Run) int64 {
+	for _, t := range []*int64{run.CompletedAt, run.FailedAt, run.CancelledAt} {
+		if t != nil {
+			return *t
+		}
+	}
+	if run.ExpiresAt != nil && run.Status == runs.StatusExpired {
+		return *run.ExpiresAt
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/assistants"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// Definition describes the desired state of an assistant. Fields left out of a
+// definition are not managed and keep whatever value the remote assistant has.
+// Likewise, only the metadata keys a definition declares are managed.
+type Definition struct {
+	// Key identifies the assistant. It is stored in the assistant's metadata under the
+	// planner's metadata key and used to find the remote assistant.
+	Key              string                     `json:"key"`
+	Name             string                     `json:"name,omitempty"`
+	Description      string                     `json:"description,omitempty"`
+	Model            string                     `json:"model"`
+	Instructions     string                     `json:"instructions,omitempty"`
+	InstructionsFile string                     `json:"instructions_file,omitempty"`
+	Tools            []assistants.Tool          `json:"tools,omitempty"`
+	ToolResources    *assistants.ToolResources  `json:"tool_resources,omitempty"`
+	Metadata         map[string]string          `json:"metadata,omitempty"`
+	Temperature      *float64                   `json:"temperature,omitempty"`
+	TopP             *float64                   `json:"top_p,omitempty"`
+	ReasoningEffort  string                     `json:"reasoning_effort,omitempty"`
+	ResponseFormat   *assistants.ResponseFormat `json:"response_format,omitempty"`
+}
+
+// Validate checks that the definition has a key and a model and does not set
+// both instructions and instructions_file
+func (d *Definition) Validate() error {
+	if d.Key == "" {
+		return errors.New("definition key is required")
+	}
+	if d.Model == "" {
+		return fmt.Errorf("definition %s: model is required", d.Key)
+	}
+	if d.Instructions != "" && d.InstructionsFile != "" {
+		return fmt.Errorf("definition %s: set instructions or instructions_file, not both", d.Key)
+	}
+	return nil
+}
+
+// LoadFile reads the definitions in a YAML or JSON file. A file holds a single
+// definition or a list of them. instructions_file paths are resolved relative to
+// the file and read into Instructions.
+func LoadFile(path string) ([]Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, so both formats go through the YAML decoder and are
+	// re-encoded as JSON to reuse the json tags of the API types.
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if doc == nil {
+		return nil, nil
+	}
+	if _, ok := doc.([]interface{}); !ok {
+		doc = []interface{}{doc}
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var defs []Definition
+	if err := json.Unmarshal(raw, &defs); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i := range defs {
+		d := &defs[i]
+		if err := d.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if d.InstructionsFile != "" {
+			file := d.InstructionsFile
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			instructions, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("%s: definition %s: %w", path, d.Key, err)
+			}
+			d.Instructions = string(instructions)
+			d.InstructionsFile = ""
+		}
+	}
+	return defs, nil
+}
+
+// LoadDir reads every .yaml, .yml and .json file in a directory, in name order,
+// and fails on duplicate keys
+func LoadDir(dir string) ([]Definition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+			if !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var defs []Definition
+	seen := make(map[string]string)
+	for _, name := range names {
+		fileDefs, err := LoadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range fileDefs {
+			if other, ok := seen[d.Key]; ok {
+				return nil, fmt.Errorf("duplicate definition key %s in %s and %s", d.Key, other, name)
+			}
+			seen[d.Key] = name
+			defs = append(defs, d)
+		}
+	}
+	return defs, nil
+}
+
+// metadata returns the desired metadata including the key
+func (d *Definition) metadata(metadataKey string) types.Metadata {
+	m := make(types.Metadata, len(d.Metadata)+1)
+	for k, v := range d.Metadata {
+		m[k] = v
+	}
+	m[metadataKey] = d.Key
+	return m
+}
@@ -0,0 +1,258 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/assistants"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+const supportYAML = `
+key: support
+name: Support Bot
+model: gpt-4o
+instructions_file: prompts/support.md
+temperature: 0.2
+tools:
+  - type: file_search
+    file_search:
+      max_num_results: 8
+  - type: function
+    function:
+      name: lookup_order
+      strict: true
+      parameters:
+        type: object
+        properties:
+          order_id: {type: string}
+        required: [order_id]
+        additionalProperties: false
+metadata:
+  team: support
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "support.yaml"), supportYAML)
+	writeFile(t, filepath.Join(dir, "prompts", "support.md"), "Help customers with orders.")
+	writeFile(t, filepath.Join(dir, "others.json"), `[{"key":"triage","model":"gpt-4o-mini","response_format":{"type":"json_object"}}]`)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	defs, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(defs) != 2 || defs[0].Key != "triage" || defs[1].Key != "support" {
+		t.Fatalf("Expected triage and support definitions, got %+v", defs)
+	}
+
+	support := defs[1]
+	if support.Instructions != "Help customers with orders." || support.InstructionsFile != "" {
+		t.Errorf("Expected instructions to be read from file, got %q", support.Instructions)
+	}
+	if *support.Tools[0].FileSearch.MaxNumResults != 8 || !*support.Tools[1].Function.Strict {
+		t.Errorf("Expected tool options to be decoded, got %+v", support.Tools)
+	}
+	if defs[0].ResponseFormat.Type != types.ResponseFormatJSONObject {
+		t.Errorf("Expected json_object format, got %+v", defs[0].ResponseFormat)
+	}
+
+	writeFile(t, filepath.Join(dir, "dup.yml"), "key: support\nmodel: gpt-4o\n")
+	if _, err := LoadDir(dir); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("Expected duplicate key error, got %v", err)
+	}
+}
+
+type fakeAPI struct {
+	mu       sync.Mutex
+	list     []assistants.Assistant
+	requests []string
+	bodies   map[string]string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	call := r.Method + " " + r.URL.Path
+	f.requests = append(f.requests, call)
+	f.bodies[call] = string(body)
+
+	switch {
+	case r.Method == "GET":
+		json.NewEncoder(w).Encode(assistants.ListAssistantsResponse{Data: f.list})
+	case r.Method == "DELETE":
+		json.NewEncoder(w).Encode(assistants.DeleteAssistantResponse{Deleted: true})
+	case r.URL.Path == "/assistants":
+		json.NewEncoder(w).Encode(assistants.Assistant{ID: "asst_new"})
+	default:
+		json.NewEncoder(w).Encode(assistants.Assistant{ID: strings.TrimPrefix(r.URL.Path, "/assistants/")})
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	name := "Support Bot"
+	old := "Old instructions."
+	temp := 0.2
+	api := &fakeAPI{
+		bodies: make(map[string]string),
+		list: []assistants.Assistant{
+			{
+				ID: "asst_support", Model: "gpt-4o", Name: &name, Instructions: &old, Temperature: &temp,
+				Tools: []assistants.Tool{{
+					Type: "file_search",
+					FileSearch: &types.FileSearchTool{
+						RankingOptions: &types.RankingOptions{Ranker: "default_2024_08_21"},
+					},
+				}},
+				Metadata: types.Metadata{DefaultMetadataKey: "support", "owner": "ops"},
+			},
+			{ID: "asst_legacy", Model: "gpt-4", Metadata: types.Metadata{DefaultMetadataKey: "legacy"}},
+			{ID: "asst_manual", Model: "gpt-4"},
+		},
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	planner := NewPlanner(&client.Client{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()})
+	planner.Prune = true
+
+	defs := []Definition{
+		{
+			Key: "support", Name: "Support Bot", Model: "gpt-4o", Instructions: "Help customers with orders.",
+			Temperature: &temp, Tools: []assistants.Tool{types.NewFileSearchTool(nil)},
+		},
+		{Key: "triage", Model: "gpt-4o-mini"},
+	}
+
+	var out bytes.Buffer
+	plan, err := planner.Sync(defs, true, &out)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(api.requests) != 1 {
+		t.Errorf("Expected dry run to only list assistants, got %v", api.requests)
+	}
+
+	expected := []struct {
+		action Action
+		key    string
+	}{{ActionUpdate, "support"}, {ActionCreate, "triage"}, {ActionDelete, "legacy"}}
+	if len(plan.Steps) != len(expected) {
+		t.Fatalf("Expected %d steps, got %+v", len(expected), plan.Steps)
+	}
+	for i, e := range expected {
+		if plan.Steps[i].Action != e.action || plan.Steps[i].Key != e.key {
+			t.Errorf("Expected step %d to %s %s, got %+v", i, e.action, e.key, plan.Steps[i])
+		}
+	}
+
+	changes := plan.Steps[0].Changes
+	if len(changes) != 1 || changes[0].Field != "instructions" {
+		t.Errorf("Expected only instructions to change, got %+v", changes)
+	}
+	if !strings.Contains(out.String(), "~ update support (asst_support)") ||
+		!strings.Contains(out.String(), "Plan: 1 to create, 1 to update, 1 to delete, 0 unchanged") {
+		t.Errorf("Unexpected plan output:\n%s", out.String())
+	}
+
+	if _, err := planner.Apply(plan); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if body := api.bodies["POST /assistants/asst_support"]; body != `{"instructions":"Help customers with orders."}` {
+		t.Errorf("Expected update to send only instructions, got %s", body)
+	}
+	if body := api.bodies["POST /assistants"]; !strings.Contains(body, `"manifest_key":"triage"`) {
+		t.Errorf("Expected created assistant to carry its key, got %s", body)
+	}
+	if _, ok := api.bodies["DELETE /assistants/asst_legacy"]; !ok {
+		t.Errorf("Expected legacy assistant to be deleted, got %v", api.requests)
+	}
+	if _, ok := api.bodies["DELETE /assistants/asst_manual"]; ok {
+		t.Error("Expected unmanaged assistant to be left alone")
+	}
+	if plan.Steps[1].AssistantID != "asst_new" {
+		t.Errorf("Expected create step to record the new ID, got %s", plan.Steps[1].AssistantID)
+	}
+}
+
+func TestDiffMetadata(t *testing.T) {
+	d := &Definition{Key: "support", Model: "gpt-4o", Metadata: map[string]string{"team": "support"}}
+	a := &assistants.Assistant{
+		ID:       "asst_support",
+		Model:    "gpt-4o",
+		Metadata: types.Metadata{DefaultMetadataKey: "support", "team": "sales", "owner": "ops"},
+	}
+
+	changes := Diff(d, a, DefaultMetadataKey)
+	if len(changes) != 1 || changes[0].Field != "metadata" {
+		t.Fatalf("Expected a metadata change, got %+v", changes)
+	}
+
+	body, err := json.Marshal(modifyRequest(d, changes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(body) != `{"metadata":{"manifest_key":"support","owner":"ops","team":"support"}}` {
+		t.Errorf("Expected undeclared keys to be kept on update, got %s", body)
+	}
+
+	a.Metadata["team"] = "support"
+	if changes := Diff(d, a, DefaultMetadataKey); len(changes) != 0 {
+		t.Errorf("Expected undeclared remote keys not to count as changes, got %+v", changes)
+	}
+}
+
+func TestDiffReasoningEffort(t *testing.T) {
+	low := types.ReasoningEffortLow
+	d := &Definition{Key: "analyst", Model: "o3-mini", ReasoningEffort: types.ReasoningEffortHigh}
+	a := &assistants.Assistant{
+		ID:              "asst_analyst",
+		Model:           "o3-mini",
+		ReasoningEffort: &low,
+		Metadata:        types.Metadata{DefaultMetadataKey: "analyst"},
+	}
+
+	changes := Diff(d, a, DefaultMetadataKey)
+	if len(changes) != 1 || changes[0].Field != "reasoning_effort" {
+		t.Fatalf("Expected a reasoning_effort change, got %+v", changes)
+	}
+	body, err := json.Marshal(modifyRequest(d, changes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(body) != `{"reasoning_effort":"high"}` {
+		t.Errorf("Expected the update to set reasoning_effort, got %s", body)
+	}
+
+	if req := createRequest(d, DefaultMetadataKey); req.ReasoningEffort == nil || *req.ReasoningEffort != "high" {
+		t.Errorf("Expected the create request to set reasoning_effort, got %v", req.ReasoningEffort)
+	}
+
+	// Definitions without reasoning_effort leave the remote value alone
+	d.ReasoningEffort = ""
+	if changes := Diff(d, a, DefaultMetadataKey); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
@@ -0,0 +1,432 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/assistants"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// DefaultMetadataKey is the metadata key that links remote assistants to definitions
+const DefaultMetadataKey = "manifest_key"
+
+// Action is what applying a step does to a remote assistant
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionNone   Action = "none"
+)
+
+// Change is a difference in a single field
+type Change struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+}
+
+// Step is the planned action for one assistant
+type Step struct {
+	Action      Action      `json:"action"`
+	Key         string      `json:"key"`
+	AssistantID string      `json:"assistant_id,omitempty"`
+	Changes     []Change    `json:"changes,omitempty"`
+	Definition  *Definition `json:"-"`
+}
+
+// Plan is the set of steps that brings remote assistants in line with the definitions
+type Plan struct {
+	Steps []Step `json:"steps"`
+}
+
+// HasChanges reports whether applying the plan would change anything
+func (p *Plan) HasChanges() bool {
+	for _, s := range p.Steps {
+		if s.Action != ActionNone {
+			return true
+		}
+	}
+	return false
+}
+
+// Write prints the plan in a human-readable form
+func (p *Plan) Write(w io.Writer) error {
+	counts := make(map[Action]int)
+	for _, s := range p.Steps {
+		counts[s.Action]++
+		var err error
+		switch s.Action {
+		case ActionCreate:
+			_, err = fmt.Fprintf(w, "+ create %s\n", s.Key)
+		case ActionDelete:
+			_, err = fmt.Fprintf(w, "- delete %s (%s)\n", s.Key, s.AssistantID)
+		case ActionUpdate:
+			_, err = fmt.Fprintf(w, "~ update %s (%s)\n", s.Key, s.AssistantID)
+			for _, c := range s.Changes {
+				if err != nil {
+					break
+				}
+				_, err = fmt.Fprintf(w, "    %s: %s -> %s\n", c.Field, formatValue(c.From), formatValue(c.To))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to delete, %d unchanged\n",
+		counts[ActionCreate], counts[ActionUpdate], counts[ActionDelete], counts[ActionNone])
+	return err
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "(none)"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// Planner compares definitions with the remote assistants and applies the difference
+type Planner struct {
+	// MetadataKey is the metadata key holding a definition's key. Defaults to DefaultMetadataKey.
+	MetadataKey string
+	// Prune plans deletes for remote assistants that carry the metadata key
+	// but have no matching definition
+	Prune bool
+
+	service *assistants.Service
+}
+
+// NewPlanner creates a new planner using the provided client
+func NewPlanner(c *client.Client) *Planner {
+	return &Planner{MetadataKey: DefaultMetadataKey, service: assistants.New(c)}
+}
+
+func (p *Planner) metadataKey() string {
+	if p.MetadataKey == "" {
+		return DefaultMetadataKey
+	}
+	return p.MetadataKey
+}
+
+// Plan lists the remote assistants and computes the steps for the definitions.
+// Assistants without the metadata key are never touched.
+func (p *Planner) Plan(defs []Definition) (*Plan, error) {
+	key := p.metadataKey()
+
+	remote := make(map[string]*assistants.Assistant)
+	var after *string
+	for {
+		limit := 100
+		resp, err := p.service.List(&assistants.ListAssistantsParams{Limit: &limit, After: after})
+		if err != nil {
+			return nil, err
+		}
+		for i := range resp.Data {
+			a := &resp.Data[i]
+			k, ok := a.Metadata[key].(string)
+			if !ok || k == "" {
+				continue
+			}
+			if other, dup := remote[k]; dup {
+				return nil, fmt.Errorf("assistants %s and %s both have %s=%s", other.ID, a.ID, key, k)
+			}
+			remote[k] = a
+		}
+		if !resp.HasMore || resp.LastID == "" {
+			break
+		}
+		last := resp.LastID
+		after = &last
+	}
+
+	plan := &Plan{}
+	seen := make(map[string]bool)
+	for i := range defs {
+		d := &defs[i]
+		if err := d.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[d.Key] {
+			return nil, fmt.Errorf("duplicate definition key %s", d.Key)
+		}
+		seen[d.Key] = true
+
+		a, ok := remote[d.Key]
+		if !ok {
+			plan.Steps = append(plan.Steps, Step{Action: ActionCreate, Key: d.Key, Definition: d})
+			continue
+		}
+		changes := Diff(d, a, key)
+		action := ActionUpdate
+		if len(changes) == 0 {
+			action = ActionNone
+		}
+		plan.Steps = append(plan.Steps, Step{Action: action, Key: d.Key, AssistantID: a.ID, Changes: changes, Definition: d})
+	}
+
+	if p.Prune {
+		var orphans []string
+		for k := range remote {
+			if !seen[k] {
+				orphans = append(orphans, k)
+			}
+		}
+		sort.Strings(orphans)
+		for _, k := range orphans {
+			plan.Steps = append(plan.Steps, Step{Action: ActionDelete, Key: k, AssistantID: remote[k].ID})
+		}
+	}
+	return plan, nil
+}
+
+// Apply executes the plan's steps in order and stops at the first error.
+// It returns the assistants that were created or updated, keyed by definition key.
+func (p *Planner) Apply(plan *Plan) (map[string]*assistants.Assistant, error) {
+	key := p.metadataKey()
+	result := make(map[string]*assistants.Assistant)
+
+	for i := range plan.Steps {
+		s := &plan.Steps[i]
+		switch s.Action {
+		case ActionCreate:
+			a, err := p.service.Create(createRequest(s.Definition, key))
+			if err != nil {
+				return result, fmt.Errorf("create %s: %w", s.Key, err)
+			}
+			s.AssistantID = a.ID
+			result[s.Key] = a
+		case ActionUpdate:
+			a, err := p.service.Modify(s.AssistantID, modifyRequest(s.Definition, s.Changes))
+			if err != nil {
+				return result, fmt.Errorf("update %s: %w", s.Key, err)
+			}
+			result[s.Key] = a
+		case ActionDelete:
+			if _, err := p.service.Delete(s.AssistantID); err != nil {
+				return result, fmt.Errorf("delete %s: %w", s.Key, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Sync plans the definitions, writes the plan to w when it is not nil and applies it
+// unless dryRun is set
+func (p *Planner) Sync(defs []Definition, dryRun bool, w io.Writer) (*Plan, error) {
+	plan, err := p.Plan(defs)
+	if err != nil {
+		return nil, err
+	}
+	if w != nil {
+		if err := plan.Write(w); err != nil {
+			return plan, err
+		}
+	}
+	if dryRun {
+		return plan, nil
+	}
+	_, err = p.Apply(plan)
+	return plan, err
+}
+
+// Diff returns the fields of the remote assistant that differ from the definition.
+// Tools, tool resources and the response format match when every value in the
+// definition equals the remote one, so defaults the API fills in are not reported.
+func Diff(d *Definition, a *assistants.Assistant, metadataKey string) []Change {
+	var changes []Change
+	add := func(field string, from, to interface{}) {
+		changes = append(changes, Change{Field: field, From: from, To: to})
+	}
+
+	if d.Model != a.Model {
+		add("model", a.Model, d.Model)
+	}
+	if d.Name != "" && d.Name != deref(a.Name) {
+		add("name", a.Name, d.Name)
+	}
+	if d.Description != "" && d.Description != deref(a.Description) {
+		add("description", a.Description, d.Description)
+	}
+	if d.Instructions != "" && d.Instructions != deref(a.Instructions) {
+		add("instructions", a.Instructions, d.Instructions)
+	}
+	if d.Tools != nil && !subset(d.Tools, a.Tools) {
+		add("tools", a.Tools, d.Tools)
+	}
+	if d.ToolResources != nil && !subset(d.ToolResources, a.ToolResources) {
+		add("tool_resources", a.ToolResources, d.ToolResources)
+	}
+	if want := d.metadata(metadataKey); !containsMetadata(want, a.Metadata) {
+		add("metadata", a.Metadata, mergeMetadata(a.Metadata, want))
+	}
+	if d.Temperature != nil && (a.Temperature == nil || *a.Temperature != *d.Temperature) {
+		add("temperature", a.Temperature, *d.Temperature)
+	}
+	if d.TopP != nil && (a.TopP == nil || *a.TopP != *d.TopP) {
+		add("top_p", a.TopP, *d.TopP)
+	}
+	if d.ReasoningEffort != "" && d.ReasoningEffort != deref(a.ReasoningEffort) {
+		add("reasoning_effort", a.ReasoningEffort, d.ReasoningEffort)
+	}
+	if d.ResponseFormat != nil && !subset(d.ResponseFormat, responseFormatOrAuto(a.ResponseFormat)) {
+		add("response_format", a.ResponseFormat, d.ResponseFormat)
+	}
+	return changes
+}
+
+func createRequest(d *Definition, metadataKey string) *assistants.CreateAssistantRequest {
+	req := &assistants.CreateAssistantRequest{
+		Model:          d.Model,
+		Tools:          d.Tools,
+		ToolResources:  d.ToolResources,
+		Metadata:       d.metadata(metadataKey),
+		Temperature:    d.Temperature,
+		TopP:           d.TopP,
+		ResponseFormat: d.ResponseFormat,
+	}
+	if d.Name != "" {
+		req.Name = &d.Name
+	}
+	if d.Description != "" {
+		req.Description = &d.Description
+	}
+	if d.Instructions != "" {
+		req.Instructions = &d.Instructions
+	}
+	if d.ReasoningEffort != "" {
+		req.ReasoningEffort = &d.ReasoningEffort
+	}
+	return req
+}
+
+// modifyRequest sets only the changed fields
+func modifyRequest(d *Definition, changes []Change) *assistants.ModifyAssistantRequest {
+	req := &assistants.ModifyAssistantRequest{}
+	for _, c := range changes {
+		switch c.Field {
+		case "model":
+			req.Model = types.Set(d.Model)
+		case "name":
+			req.Name = types.Set(d.Name)
+		case "description":
+			req.Description = types.Set(d.Description)
+		case "instructions":
+			req.Instructions = types.Set(d.Instructions)
+		case "tools":
+			req.Tools = types.Set(d.Tools)
+		case "tool_resources":
+			req.ToolResources = types.Set(*d.ToolResources)
+		case "metadata":
+			// Diff stores the remote metadata merged with the definition's keys
+			if m, ok := c.To.(types.Metadata); ok {
+				req.Metadata = types.Set(m)
+			}
+		case "temperature":
+			req.Temperature = types.Set(*d.Temperature)
+		case "top_p":
+			req.TopP = types.Set(*d.TopP)
+		case "reasoning_effort":
+			req.ReasoningEffort = types.Set(d.ReasoningEffort)
+		case "response_format":
+			req.ResponseFormat = types.Set(*d.ResponseFormat)
+		}
+	}
+	return req
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// mergeMetadata returns the remote metadata with the wanted keys set, since the API
+// replaces the whole metadata object on update
+func mergeMetadata(remote, want types.Metadata) types.Metadata {
+	merged := make(types.Metadata, len(remote)+len(want))
+	for k, v := range remote {
+		merged[k] = v
+	}
+	for k, v := range want {
+		merged[k] = v
+	}
+	return merged
+}
+
+func responseFormatOrAuto(f *assistants.ResponseFormat) *assistants.ResponseFormat {
+	if f == nil {
+		return types.AutoResponseFormat()
+	}
+	return f
+}
+
+// containsMetadata reports whether got has every key of want with the same value, compared
+// as strings, the only value type the API stores. Other remote keys are not managed.
+func containsMetadata(want, got types.Metadata) bool {
+	for k, v := range want {
+		g, ok := got[k]
+		if !ok || fmt.Sprint(v) != fmt.Sprint(g) {
+			return false
+		}
+	}
+	return true
+}
+
+// subset reports whether want, encoded as JSON, is contained in got: objects match when
+// every key of want matches, arrays when they have the same length and match element-wise
+func subset(want, got interface{}) bool {
+	return containsJSON(normalize(want), normalize(got))
+}
+
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+func containsJSON(want, got interface{}) bool {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range w {
+			if !containsJSON(v, g[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !containsJSON(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(want, got)
+	}
+}
@@ -70,12 +70,35 @@ type Attachment struct {
 	Tools  []Tool `json:"tools,omitempty"`
 }
 
-// Tool represents a tool that can use an attachment
-type Tool struct {
-	Type string `json:"type"`
+// Tool represents a tool that can use an attachment. Attachments only use the Type field.
+type Tool = types.Tool
+
+// ContentPart represents a content part of a message being created.
+// A slice of content parts can be used as CreateMessageRequest.Content.
+type ContentPart struct {
+	Type      string     `json:"type"`
+	Text      string     `json:"text,omitempty"`
+	ImageURL  *ImageURL  `json:"image_url,omitempty"`
+	ImageFile *ImageFile `json:"image_file,omitempty"`
+}
+
+// TextPart creates a text content part
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: "text", Text: text}
+}
+
+// ImageURLPart creates an image URL content part
+func ImageURLPart(url, detail string) ContentPart {
+	return ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: url, Detail: detail}}
+}
+
+// ImageFilePart creates an image file content part
+func ImageFilePart(fileID, detail string) ContentPart {
+	return ContentPart{Type: "image_file", ImageFile: &ImageFile{FileID: fileID, Detail: detail}}
 }
 
-// CreateMessageRequest represents the request to create a new message
+// CreateMessageRequest represents the request to create a new message.
+// Content is either a string or a slice of ContentPart.
 type CreateMessageRequest struct {
 	Role        string         `json:"role"`
 	Content     interface{}    `json:"content"`
@@ -120,6 +143,10 @@ func New(c *client.Client) *Service {
 
 // Create creates a new message in a thread
 func (s *Service) Create(threadID string, req *CreateMessageRequest) (*Message, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -142,6 +169,10 @@ func (s *Service) Create(threadID string, req *CreateMessageRequest) (*Message,
 
 // List returns a list of messages for a thread
 func (s *Service) List(threadID string, params *ListMessagesParams) (*ListMessagesResponse, error) {
+	if err := s.client.Validate(params); err != nil {
+		return nil, err
+	}
+
 	url := fmt.Sprintf("%s/threads/%s/messages", s.client.BaseURL, threadID)
 	if params != nil {
 		query := make(map[string]string)
@@ -204,6 +235,10 @@ func (s *Service) Get(threadID, messageID string) (*Message, error) {
 
 // Modify modifies a message's metadata
 func (s *Service) Modify(threadID, messageID string, metadata types.Metadata) (*Message, error) {
+	if err := s.client.Validate(metadata); err != nil {
+		return nil, err
+	}
+
 	body, err := json.Marshal(map[string]interface{}{
 		"metadata": metadata,
 	})
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/greenstorm5417/openai-assistants-go/client"
@@ -315,3 +316,32 @@ func TestMessageContent(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateMessageValidation(t *testing.T) {
+	tests := []struct {
+		req  CreateMessageRequest
+		path string
+	}{
+		{CreateMessageRequest{Role: "user", Content: "hi"}, ""},
+		{CreateMessageRequest{Role: "user", Content: []ContentPart{TextPart("hi"), ImageFilePart("file_1", "")}}, ""},
+		{CreateMessageRequest{Role: "user"}, "content"},
+		{CreateMessageRequest{Role: "user", Content: []ContentPart{ImageURLPart("", "auto")}}, "content[0].image_url.url"},
+		{CreateMessageRequest{Role: "bot", Content: "hi"}, "role"},
+		{CreateMessageRequest{Role: "user", Content: "hi", Attachments: []Attachment{{Tools: []Tool{{Type: "function"}}}}}, "attachments[0].file_id"},
+		{CreateMessageRequest{Role: "user", Content: "hi", Attachments: []Attachment{{FileID: "f", Tools: []Tool{{Type: "function"}}}}}, "attachments[0].tools[0].type"},
+		{CreateMessageRequest{Role: "user", Content: "hi", Metadata: types.Metadata{"count": 3}}, `metadata["count"]`},
+	}
+
+	for _, tt := range tests {
+		err := tt.req.Validate()
+		if tt.path == "" {
+			if err != nil {
+				t.Errorf("Expected %+v to be valid, got %v", tt.req, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.path+":") {
+			t.Errorf("Expected error at %s, got %v", tt.path, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package messages
+
+import (
+	"fmt"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// Validate checks the role, content and attachments and returns every problem found
+func (r *CreateMessageRequest) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var v types.Validator
+	v.Required("role", r.Role)
+	v.OneOf("role", r.Role, "user", "assistant")
+
+	switch content := r.Content.(type) {
+	case nil:
+		v.Addf("content", "is required")
+	case string:
+		v.Required("content", content)
+	case []ContentPart:
+		if len(content) == 0 {
+			v.Addf("content", "is required")
+		}
+		for i, part := range content {
+			validateContentPart(&v, fmt.Sprintf("content[%d]", i), part)
+		}
+	}
+
+	for i, a := range r.Attachments {
+		path := fmt.Sprintf("attachments[%d]", i)
+		v.Required(path+".file_id", a.FileID)
+		for j, tool := range a.Tools {
+			v.OneOf(fmt.Sprintf("%s.tools[%d].type", path, j), tool.Type, types.ToolTypeCodeInterpreter, types.ToolTypeFileSearch)
+		}
+	}
+	v.Metadata("metadata", r.Metadata)
+	return v.Err()
+}
+
+// Validate checks the list parameters
+func (p *ListMessagesParams) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	var v types.Validator
+	v.ListParams(p.Limit, p.Order)
+	return v.Err()
+}
+
+func validateContentPart(v *types.Validator, path string, part ContentPart) {
+	switch part.Type {
+	case "text":
+		v.Required(path+".text", part.Text)
+	case "image_url":
+		if part.ImageURL == nil || part.ImageURL.URL == "" {
+			v.Addf(path+".image_url.url", "is required")
+		}
+	case "image_file":
+		if part.ImageFile == nil || part.ImageFile.FileID == "" {
+			v.Addf(path+".image_file.file_id", "is required")
+		}
+	default:
+		v.Addf(path+".type", "must be one of text, image_url, image_file, got %q", part.Type)
+	}
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/assistants"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// ErrUnknownModel is returned in Reject mode for requests whose model has no profile
+var ErrUnknownModel = errors.New("unknown model")
+
+// Mode selects what Check does with fields a model does not support
+type Mode int
+
+const (
+	// Strip clears unsupported fields from the request before it is sent
+	Strip Mode = iota
+	// Reject fails the request with a *types.CapabilityError
+	Reject
+)
+
+var (
+	chatModel = types.Capabilities{
+		Temperature:    true,
+		TopP:           true,
+		ResponseFormat: true,
+	}
+	reasoningModel = types.Capabilities{
+		ReasoningEffort: true,
+		ResponseFormat:  true,
+		Tools:           []string{types.ToolTypeFunction, types.ToolTypeFileSearch},
+	}
+)
+
+// DefaultProfiles returns the built-in capabilities of each model family
+func DefaultProfiles() map[string]types.Capabilities {
+	return map[string]types.Capabilities{
+		"gpt-3.5-turbo": chatModel,
+		"gpt-4":         chatModel,
+		"gpt-4-turbo":   chatModel,
+		"gpt-4o":        chatModel,
+		"gpt-4.1":       chatModel,
+		"o1":            reasoningModel,
+		"o3":            reasoningModel,
+		"o3-mini":       reasoningModel,
+		"o4-mini":       reasoningModel,
+	}
+}
+
+// Registry maps model families to their capabilities and checks requests against them.
+// It implements client.ModelPolicy; set it as the client's Models to check every
+// assistant and run request before it is sent. It is safe for concurrent use.
+type Registry struct {
+	// Mode selects whether unsupported fields are stripped or rejected
+	Mode Mode
+	// OnStrip, if set, is called with the fields stripped from a request
+	OnStrip func(model string, fields []string)
+	// Resolve, if set, returns the model of an assistant. It is used for requests that
+	// do not set a model, such as runs using the assistant's model; see AssistantModel.
+	Resolve func(assistantID string) (string, error)
+
+	mu       sync.RWMutex
+	families map[string]types.Capabilities
+}
+
+// NewRegistry creates a registry holding the default profiles
+func NewRegistry() *Registry {
+	return &Registry{families: DefaultProfiles()}
+}
+
+// Set sets the capabilities of a model family, replacing any existing profile
+func (r *Registry) Set(family string, caps types.Capabilities) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.families == nil {
+		r.families = make(map[string]types.Capabilities)
+	}
+	r.families[family] = caps
+}
+
+// Load reads a JSON object mapping model families to capabilities and sets each of them
+func (r *Registry) Load(rd io.Reader) error {
+	var profiles map[string]types.Capabilities
+	if err := json.NewDecoder(rd).Decode(&profiles); err != nil {
+		return fmt.Errorf("failed to decode model profiles: %w", err)
+	}
+	for family, caps := range profiles {
+		r.Set(family, caps)
+	}
+	return nil
+}
+
+// LoadFile overrides profiles from a local JSON file, such as
+//
+//	{"o3-mini": {"reasoning_effort": true, "tools": ["function"]}}
+func (r *Registry) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.Load(f)
+}
+
+// Lookup returns the capabilities of model. A family matches the model of the same name
+// and its variants, so "gpt-4o" covers "gpt-4o-mini" and "gpt-4o-2024-08-06";
+// the longest matching family wins.
+func (r *Registry) Lookup(model string) (types.Capabilities, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		best  string
+		caps  types.Capabilities
+		found bool
+	)
+	for family, c := range r.families {
+		if model != family && !strings.HasPrefix(model, family+"-") {
+			continue
+		}
+		if !found || len(family) > len(best) {
+			best, caps, found = family, c, true
+		}
+	}
+	return caps, found
+}
+
+// AssistantModel returns a Resolve function that retrieves assistants through c,
+// so they are served from the client's cache when it has one
+func AssistantModel(c *client.Client) func(assistantID string) (string, error) {
+	service := assistants.New(c)
+	return func(assistantID string) (string, error) {
+		a, err := service.Get(assistantID)
+		if err != nil {
+			return "", err
+		}
+		return a.Model, nil
+	}
+}
+
+// Check conforms req to the capabilities of its target model and returns the request to send;
+// req itself is never modified. Requests that do not set a model use the model of the
+// assistant, found with Resolve. In Strip mode, requests whose model cannot be determined
+// or has no profile are sent unchanged; in Reject mode they fail with ErrUnknownModel.
+func (r *Registry) Check(req interface{}, assistantID string) (interface{}, error) {
+	mr, ok := req.(types.ModelRequest)
+	if !ok {
+		return req, nil
+	}
+
+	// Requests without model-dependent fields are sent as they are
+	probe := &types.Conformer{Caps: types.Capabilities{Tools: []string{}}}
+	mr.Conform(probe)
+	if len(probe.Fields()) == 0 {
+		return req, nil
+	}
+
+	model := mr.TargetModel()
+	if model == "" && assistantID != "" && r.Resolve != nil {
+		resolved, err := r.Resolve(assistantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the model of assistant %s: %w", assistantID, err)
+		}
+		model = resolved
+	}
+	caps, ok := r.Lookup(model)
+	if !ok {
+		if r.Mode != Reject {
+			return req, nil
+		}
+		if model == "" {
+			return nil, fmt.Errorf("%w: the request uses the model of assistant %s and Resolve is not set", ErrUnknownModel, assistantID)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnknownModel, model)
+	}
+
+	c := &types.Conformer{Caps: caps, Strip: r.Mode == Strip}
+	conformed := mr.Conform(c)
+	fields := c.Fields()
+	if len(fields) == 0 {
+		return req, nil
+	}
+	if r.Mode == Reject {
+		return nil, &types.CapabilityError{Model: model, Fields: fields}
+	}
+	if r.OnStrip != nil {
+		r.OnStrip(model, fields)
+	}
+	return conformed, nil
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/assistants"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+func TestLookup(t *testing.T) {
+	registry := NewRegistry()
+
+	tests := []struct {
+		model     string
+		found     bool
+		reasoning bool
+	}{
+		{"gpt-4o", true, false},
+		{"gpt-4o-mini-2024-07-18", true, false},
+		{"o3-mini", true, true},
+		{"o3-mini-2025-01-31", true, true},
+		{"o1-preview", true, true},
+		{"gpt-4omni", false, false},
+		{"custom-model", false, false},
+	}
+
+	for _, tt := range tests {
+		caps, found := registry.Lookup(tt.model)
+		if found != tt.found {
+			t.Errorf("%s: expected found %v, got %v", tt.model, tt.found, found)
+		}
+		if caps.ReasoningEffort != tt.reasoning || (found && caps.Temperature == tt.reasoning) {
+			t.Errorf("%s: unexpected capabilities %+v", tt.model, caps)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	profiles := `{"o3-mini": {"reasoning_effort": true, "tools": ["function"]}, "acme": {"temperature": true}}`
+	if err := os.WriteFile(path, []byte(profiles), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewRegistry()
+	if err := registry.LoadFile(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	caps, _ := registry.Lookup("o3-mini")
+	if caps.SupportsTool(types.ToolTypeFileSearch) || !caps.SupportsTool(types.ToolTypeFunction) {
+		t.Errorf("Expected the override to replace the o3-mini tools, got %v", caps.Tools)
+	}
+	if caps, _ := registry.Lookup("o3"); !caps.SupportsTool(types.ToolTypeFileSearch) {
+		t.Error("Expected other profiles to be kept")
+	}
+	if _, found := registry.Lookup("acme-large"); !found {
+		t.Error("Expected the new family to be added")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	temperature := 0.5
+	effort := types.ReasoningEffortHigh
+	newRequest := func() *assistants.CreateAssistantRequest {
+		return &assistants.CreateAssistantRequest{
+			Model:           "o3-mini",
+			Temperature:     &temperature,
+			ReasoningEffort: &effort,
+			Tools:           []assistants.Tool{types.NewCodeInterpreterTool(), types.NewFileSearchTool(nil)},
+		}
+	}
+
+	registry := NewRegistry()
+	var stripped []string
+	registry.OnStrip = func(model string, fields []string) { stripped = fields }
+
+	req := newRequest()
+	checked, err := registry.Check(req, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sent := checked.(*assistants.CreateAssistantRequest)
+	if sent.Temperature != nil || sent.ReasoningEffort == nil {
+		t.Errorf("Expected temperature to be stripped and reasoning_effort kept, got %v and %v", sent.Temperature, sent.ReasoningEffort)
+	}
+	if len(sent.Tools) != 1 || sent.Tools[0].Type != types.ToolTypeFileSearch {
+		t.Errorf("Expected only file_search to remain, got %+v", sent.Tools)
+	}
+	if want := []string{"temperature", "tools[0]"}; !reflect.DeepEqual(stripped, want) {
+		t.Errorf("Expected stripped fields %v, got %v", want, stripped)
+	}
+	if req.Temperature == nil || len(req.Tools) != 2 {
+		t.Error("Expected the caller's request to be left unchanged")
+	}
+
+	registry.Mode = Reject
+	req = newRequest()
+	_, err = registry.Check(req, "")
+	var capErr *types.CapabilityError
+	if !errors.As(err, &capErr) || capErr.Model != "o3-mini" || len(capErr.Fields) != 2 {
+		t.Fatalf("Expected a capability error for two fields, got %v", err)
+	}
+	if req.Temperature == nil || len(req.Tools) != 2 {
+		t.Error("Expected a rejected request to be left unchanged")
+	}
+
+	effortOnChat := &runs.CreateRunRequest{AssistantID: "asst_123", ReasoningEffort: &effort}
+	if _, err := registry.Check(effortOnChat, "asst_123"); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("Expected runs on an unresolved assistant model to be rejected, got %v", err)
+	}
+	registry.Resolve = func(assistantID string) (string, error) { return "gpt-4o", nil }
+	if _, err := registry.Check(effortOnChat, "asst_123"); !errors.As(err, &capErr) || capErr.Model != "gpt-4o" {
+		t.Errorf("Expected reasoning_effort on the assistant's gpt-4o to be rejected, got %v", err)
+	}
+	if _, err := registry.Check(&runs.CreateRunRequest{AssistantID: "asst_123"}, "asst_123"); err != nil {
+		t.Errorf("Expected a run without model-dependent fields to pass, got %v", err)
+	}
+	if _, err := registry.Check(&assistants.CreateAssistantRequest{Model: "acme", TopP: &temperature}, ""); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("Expected a model without a profile to be rejected, got %v", err)
+	}
+
+	var nilReq *assistants.CreateAssistantRequest
+	if _, err := registry.Check(nilReq, ""); err != nil {
+		t.Errorf("Expected no error for a nil request, got %v", err)
+	}
+}
+
+func TestClientModels(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(assistants.Assistant{ID: "asst_123", Model: "o1"})
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+		Models:     NewRegistry(),
+	}
+
+	topP := 0.9
+	_, err := assistants.New(c).Create(&assistants.CreateAssistantRequest{Model: "o1", TopP: &topP})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := body["top_p"]; ok {
+		t.Errorf("Expected top_p to be stripped, got %v", body)
+	}
+
+	// Runs without a model override are checked against the assistant's model
+	registry := NewRegistry()
+	registry.Resolve = AssistantModel(c)
+	c.Models = registry
+	temperature := 0.2
+	req := &runs.CreateThreadAndRunRequest{AssistantID: "asst_123", Temperature: &temperature}
+	if _, err := runs.New(c).CreateThreadAndRun(req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := body["temperature"]; ok {
+		t.Errorf("Expected temperature to be stripped for the assistant's o1 model, got %v", body)
+	}
+	if req.Temperature == nil {
+		t.Error("Expected the caller's request to be left unchanged")
+	}
+}
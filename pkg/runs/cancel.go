@@ -0,0 +1,100 @@
+package runs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CancelAndWait cancels a run and polls it until it reaches cancelled or another terminal status
+func (s *Service) CancelAndWait(ctx context.Context, threadID, runID string) (*Run, error) {
+	run, err := s.Cancel(threadID, runID)
+	if err != nil {
+		// The run may have finished before the cancel request arrived
+		current, getErr := s.Get(threadID, runID)
+		if getErr != nil || !current.Status.IsTerminal() {
+			return nil, err
+		}
+		return current, nil
+	}
+
+	if run.Status.IsTerminal() {
+		return run, nil
+	}
+
+	return NewWatcher(s).WatchUntil(ctx, threadID, runID, func(r *Run) bool {
+		return r.Status.IsTerminal()
+	})
+}
+
+// Shutdown cancels every non-terminal run tracked by the client and waits for the
+// cancellations until ctx is done. It requires client.RunTracker to be set.
+func (s *Service) Shutdown(ctx context.Context) error {
+	tracker := s.client.RunTracker
+	if tracker == nil {
+		return errors.New("run tracking is not enabled on the client")
+	}
+
+	refs := tracker.Runs()
+	errs := make([]error, len(refs))
+
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.CancelAndWait(ctx, ref.ThreadID, ref.RunID); err != nil {
+				errs[i] = fmt.Errorf("failed to cancel run %s: %w", ref.RunID, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// track records a run created through this service in the client's run tracker,
+// or forgets it once it is terminal
+func (s *Service) track(run *Run) {
+	tracker := s.client.RunTracker
+	if tracker == nil || run.ID == "" {
+		return
+	}
+
+	if run.Status.IsTerminal() {
+		tracker.Untrack(run.ID)
+	} else {
+		tracker.Track(run.ThreadID, run.ID)
+	}
+}
+
+// untrackFinished forgets a run that reached a terminal status. Runs that are only
+// read are never added, so runs owned by other processes are not cancelled by Shutdown.
+func (s *Service) untrackFinished(run *Run) {
+	tracker := s.client.RunTracker
+	if tracker == nil || run.ID == "" || !run.Status.IsTerminal() {
+		return
+	}
+	tracker.Untrack(run.ID)
+}
+
+// trackEvent tracks the run carried by a streamed thread.run.* event. created is set
+// for streams that create the run; other streams only forget finished runs.
+func (s *Service) trackEvent(event string, data json.RawMessage, created bool) {
+	if s.client.RunTracker == nil || !strings.HasPrefix(event, "thread.run.") || strings.HasPrefix(event, "thread.run.step.") {
+		return
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return
+	}
+	if created {
+		s.track(&run)
+	} else {
+		s.untrackFinished(&run)
+	}
+}
@@ -0,0 +1,113 @@
+package runs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+)
+
+// fakeRunServer serves runs whose status advances from cancelling to cancelled after a cancel request
+type fakeRunServer struct {
+	mu       sync.Mutex
+	statuses map[string]RunStatus
+	polls    map[string]int
+}
+
+func (f *fakeRunServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == "POST" && len(parts) == 3:
+		runID := "run_" + string(rune('a'+len(f.statuses)))
+		f.statuses[runID] = StatusQueued
+		json.NewEncoder(w).Encode(Run{ID: runID, ThreadID: parts[1], Status: StatusQueued})
+	case r.Method == "POST" && len(parts) == 5 && parts[4] == "cancel":
+		f.statuses[parts[3]] = StatusCancelling
+		json.NewEncoder(w).Encode(Run{ID: parts[3], ThreadID: parts[1], Status: StatusCancelling})
+	case r.Method == "GET" && len(parts) == 4:
+		runID := parts[3]
+		f.polls[runID]++
+		if f.statuses[runID] == StatusCancelling && f.polls[runID] > 1 {
+			f.statuses[runID] = StatusCancelled
+		}
+		json.NewEncoder(w).Encode(Run{ID: runID, ThreadID: parts[1], Status: f.statuses[runID]})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeRunService(t *testing.T, tracker *client.RunTracker) (*Service, func()) {
+	fake := &fakeRunServer{statuses: make(map[string]RunStatus), polls: make(map[string]int)}
+	server := httptest.NewServer(fake)
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+		RunTracker: tracker,
+	}
+
+	service := New(c)
+	service.PollInterval = time.Millisecond
+	return service, server.Close
+}
+
+func TestCancelAndWait(t *testing.T) {
+	service, closeServer := newFakeRunService(t, nil)
+	defer closeServer()
+
+	run, err := service.Create("thread_123", &CreateRunRequest{AssistantID: "asst_123"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	run, err = service.CancelAndWait(context.Background(), "thread_123", run.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != StatusCancelled {
+		t.Errorf("Expected status cancelled, got %s", run.Status)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	tracker := client.NewRunTracker()
+	service, closeServer := newFakeRunService(t, tracker)
+	defer closeServer()
+
+	// Runs that are only read, such as runs owned by another worker, are not tracked
+	if _, err := service.Get("thread_other", "run_other"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tracker.Runs()) != 0 {
+		t.Fatalf("Expected runs read through Get not to be tracked, got %v", tracker.Runs())
+	}
+
+	for _, thread := range []string{"thread_1", "thread_2"} {
+		if _, err := service.Create(thread, &CreateRunRequest{AssistantID: "asst_123"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if len(tracker.Runs()) != 2 {
+		t.Fatalf("Expected 2 tracked runs, got %d", len(tracker.Runs()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := service.Shutdown(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tracker.Runs()) != 0 {
+		t.Errorf("Expected no tracked runs after shutdown, got %v", tracker.Runs())
+	}
+}
@@ -0,0 +1,61 @@
+package runs
+
+import "github.com/greenstorm5417/openai-assistants-go/pkg/types"
+
+// TargetModel returns the model override, or "" when the run uses the assistant's model
+func (r *CreateRunRequest) TargetModel() string {
+	if r == nil || r.Model == nil {
+		return ""
+	}
+	return *r.Model
+}
+
+// Conform reports the model-dependent fields of the request and returns a copy
+// with the fields c strips cleared
+func (r *CreateRunRequest) Conform(c *types.Conformer) types.ModelRequest {
+	if r == nil {
+		return r
+	}
+
+	out := *r
+	conformRunOptions(c, &out.Temperature, &out.TopP, &out.ReasoningEffort, &out.ResponseFormat, &out.Tools)
+	return &out
+}
+
+// TargetModel returns the model override, or "" when the run uses the assistant's model
+func (r *CreateThreadAndRunRequest) TargetModel() string {
+	if r == nil || r.Model == nil {
+		return ""
+	}
+	return *r.Model
+}
+
+// Conform reports the model-dependent fields of the request and returns a copy
+// with the fields c strips cleared
+func (r *CreateThreadAndRunRequest) Conform(c *types.Conformer) types.ModelRequest {
+	if r == nil {
+		return r
+	}
+
+	out := *r
+	conformRunOptions(c, &out.Temperature, &out.TopP, &out.ReasoningEffort, &out.ResponseFormat, &out.Tools)
+	return &out
+}
+
+// conformRunOptions reports the model-dependent options shared by both run request shapes,
+// clearing those c strips
+func conformRunOptions(c *types.Conformer, temperature, topP **float64, effort **string, format **types.ResponseFormat, tools *[]Tool) {
+	if c.Param("temperature", *temperature != nil, c.Caps.Temperature) {
+		*temperature = nil
+	}
+	if c.Param("top_p", *topP != nil, c.Caps.TopP) {
+		*topP = nil
+	}
+	if c.Param("reasoning_effort", *effort != nil, c.Caps.ReasoningEffort) {
+		*effort = nil
+	}
+	if c.ResponseFormat("response_format", *format) {
+		*format = nil
+	}
+	*tools = c.Tools("tools", *tools)
+}
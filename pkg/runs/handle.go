@@ -0,0 +1,204 @@
+package runs
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+)
+
+// ActionHandler resolves a run that needs action, typically by submitting tool outputs,
+// and returns the updated run
+type ActionHandler func(ctx context.Context, run *Run) (*Run, error)
+
+// RunHandle tracks a run that is driven to completion in the background
+type RunHandle struct {
+	service  *Service
+	threadID string
+	runID    string
+	done     chan struct{}
+
+	mu  sync.Mutex
+	run *Run
+	err error
+}
+
+// Start creates a run and returns a handle while the run is polled in the background.
+// When onAction is set it is called every time the run needs action; otherwise the handle
+// settles as soon as the run is terminal or needs action. Polling stops when ctx is done.
+func (s *Service) Start(ctx context.Context, threadID string, req *CreateRunRequest, onAction ActionHandler) (*RunHandle, error) {
+	run, err := s.Create(threadID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &RunHandle{
+		service:  s,
+		threadID: run.ThreadID,
+		runID:    run.ID,
+		done:     make(chan struct{}),
+		run:      run,
+	}
+	if h.threadID == "" {
+		h.threadID = threadID
+	}
+
+	go h.poll(ctx, onAction)
+	return h, nil
+}
+
+func (h *RunHandle) poll(ctx context.Context, onAction ActionHandler) {
+	defer close(h.done)
+
+	watcher := NewWatcher(h.service)
+	for {
+		run, err := watcher.Watch(ctx, h.threadID, h.runID)
+		if run != nil {
+			h.setRun(run)
+		}
+		if err != nil {
+			h.setErr(err)
+			return
+		}
+		if run.Status.IsTerminal() || onAction == nil {
+			return
+		}
+
+		updated, err := onAction(ctx, run)
+		if err != nil {
+			h.setErr(err)
+			return
+		}
+		if updated != nil {
+			h.setRun(updated)
+		}
+	}
+}
+
+func (h *RunHandle) setRun(run *Run) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.run = run
+}
+
+func (h *RunHandle) setErr(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.err = err
+}
+
+// ID returns the run ID
+func (h *RunHandle) ID() string {
+	return h.runID
+}
+
+// ThreadID returns the ID of the thread the run belongs to
+func (h *RunHandle) ThreadID() string {
+	return h.threadID
+}
+
+// Done returns a channel that is closed once the handle has settled
+func (h *RunHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Run returns the latest snapshot of the run
+func (h *RunHandle) Run() *Run {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.run
+}
+
+// Err returns the error that stopped the background polling, if any
+func (h *RunHandle) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// Wait blocks until the handle settles or ctx is done and returns the final run
+func (h *RunHandle) Wait(ctx context.Context) (*Run, error) {
+	select {
+	case <-h.done:
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return h.run, h.err
+	case <-ctx.Done():
+		return h.Run(), ctx.Err()
+	}
+}
+
+// Cancel requests cancellation of the run. The handle settles once the run is cancelled.
+func (h *RunHandle) Cancel() error {
+	run, err := h.service.Cancel(h.threadID, h.runID)
+	if err != nil {
+		return err
+	}
+	h.setRun(run)
+	return nil
+}
+
+// Messages returns the messages created by the run, oldest first
+func (h *RunHandle) Messages() ([]messages.Message, error) {
+	service := messages.New(h.service.client)
+	order := "asc"
+	runID := h.runID
+	params := &messages.ListMessagesParams{Order: &order, RunID: &runID}
+
+	var result []messages.Message
+	for {
+		page, err := service.List(h.threadID, params)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, page.Data...)
+		if !page.HasMore || page.LastID == "" {
+			return result, nil
+		}
+		after := page.LastID
+		params.After = &after
+	}
+}
+
+// WaitAny blocks until the first of the handles settles and returns it
+func WaitAny(ctx context.Context, handles ...*RunHandle) (*RunHandle, error) {
+	if len(handles) == 0 {
+		return nil, errors.New("no run handles to wait on")
+	}
+
+	first := make(chan *RunHandle, len(handles))
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for _, h := range handles {
+		go func() {
+			select {
+			case <-h.done:
+				first <- h
+			case <-stop:
+			}
+		}()
+	}
+
+	select {
+	case h := <-first:
+		return h, h.Err()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// WaitAll blocks until every handle settles and returns the final runs in the same order.
+// The returned error joins the errors of all handles.
+func WaitAll(ctx context.Context, handles ...*RunHandle) ([]*Run, error) {
+	result := make([]*Run, len(handles))
+	errs := make([]error, len(handles))
+	for i, h := range handles {
+		result[i], errs[i] = h.Wait(ctx)
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+	}
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,143 @@
+package runs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+)
+
+func TestStart(t *testing.T) {
+	var mu sync.Mutex
+	submitted := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/messages"):
+			if r.URL.Query().Get("run_id") != "run_123" {
+				t.Errorf("Expected messages to be filtered by run, got %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode(messages.ListMessagesResponse{Data: []messages.Message{{ID: "msg_123", Role: "assistant"}}})
+		case strings.HasSuffix(r.URL.Path, "/submit_tool_outputs"):
+			submitted = true
+			json.NewEncoder(w).Encode(Run{ID: "run_123", ThreadID: "thread_123", Status: StatusQueued})
+		case r.Method == "POST":
+			json.NewEncoder(w).Encode(Run{ID: "run_123", ThreadID: "thread_123", Status: StatusQueued})
+		default:
+			run := Run{ID: "run_123", ThreadID: "thread_123", Status: StatusRequiresAction}
+			if submitted {
+				run.Status = StatusCompleted
+			}
+			json.NewEncoder(w).Encode(run)
+		}
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+	service := New(c)
+	service.PollInterval = time.Millisecond
+
+	handle, err := service.Start(context.Background(), "thread_123", &CreateRunRequest{AssistantID: "asst_123"},
+		func(ctx context.Context, run *Run) (*Run, error) {
+			return service.SubmitToolOutputs(run.ThreadID, run.ID, &SubmitToolOutputsRequest{
+				ToolOutputs: []ToolOutput{{ToolCallID: "call_123", Output: "42"}},
+			})
+		})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	run, err := handle.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != StatusCompleted {
+		t.Errorf("Expected status completed, got %s", run.Status)
+	}
+
+	select {
+	case <-handle.Done():
+	default:
+		t.Error("Expected Done to be closed")
+	}
+
+	msgs, err := handle.Messages()
+	if err != nil || len(msgs) != 1 {
+		t.Errorf("Expected one message, got %v (%v)", msgs, err)
+	}
+}
+
+func TestWaitAnyAndAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var runID string
+		if r.Method == "POST" {
+			runID = "run_" + strings.Split(r.URL.Path, "/")[2]
+		} else {
+			runID = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		}
+
+		run := Run{ID: runID, ThreadID: strings.TrimPrefix(runID, "run_"), Status: StatusInProgress}
+		if r.Method == "GET" && runID == "run_fast" {
+			run.Status = StatusCompleted
+		}
+		json.NewEncoder(w).Encode(run)
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+	service := New(c)
+	service.PollInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slow, err := service.Start(ctx, "slow", &CreateRunRequest{AssistantID: "asst_123"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	fast, err := service.Start(ctx, "fast", &CreateRunRequest{AssistantID: "asst_123"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	first, err := WaitAny(context.Background(), slow, fast)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first != fast {
+		t.Errorf("Expected the fast run to settle first, got %s", first.ID())
+	}
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer waitCancel()
+	if _, err := WaitAll(waitCtx, slow, fast); err != context.DeadlineExceeded {
+		t.Errorf("Expected WaitAll to time out on the slow run, got %v", err)
+	}
+
+	cancel()
+	runs, err := WaitAll(context.Background(), fast, slow)
+	if err == nil {
+		t.Error("Expected the cancelled handle to report an error")
+	}
+	if runs[0].Status != StatusCompleted {
+		t.Errorf("Expected the fast run to be completed, got %s", runs[0].Status)
+	}
+}
@@ -0,0 +1,169 @@
+package runs
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+const (
+	// MetadataRetryOf is the metadata key linking a retried run to the first attempt
+	MetadataRetryOf = "retry_of"
+	// MetadataRetryAttempt is the metadata key holding the attempt number of a retried run
+	MetadataRetryAttempt = "retry_attempt"
+)
+
+// DefaultRetryableCodes are the last_error codes retried when a policy does not set its own
+var DefaultRetryableCodes = []string{"server_error", "rate_limit_exceeded"}
+
+// RetryPolicy controls how runs that fail with a transient last_error are re-created
+type RetryPolicy struct {
+	// MaxAttempts is the total number of runs created, including the first. Defaults to 3.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Defaults to one second.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries. Defaults to 30 seconds.
+	MaxBackoff time.Duration
+	// RetryableCodes are the last_error codes that trigger a retry. Defaults to DefaultRetryableCodes.
+	RetryableCodes []string
+	// Wait drives a run until it is finished. Defaults to polling until the run is terminal or needs action;
+	// set it to a function that also submits tool outputs for runs using function tools.
+	Wait func(ctx context.Context, run *Run) (*Run, error)
+}
+
+// RetryResult is the outcome of CreateWithRetry
+type RetryResult struct {
+	// Run is the last attempt
+	Run *Run
+	// Attempts holds the final snapshot of every attempt, in order
+	Attempts []*Run
+}
+
+// IsRetryable reports whether the run failed with a retryable last_error code
+func (p *RetryPolicy) IsRetryable(run *Run) bool {
+	if run.Status != StatusFailed || run.LastError == nil {
+		return false
+	}
+
+	codes := p.RetryableCodes
+	if len(codes) == 0 {
+		codes = DefaultRetryableCodes
+	}
+	for _, code := range codes {
+		if run.LastError.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = time.Second
+	}
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+
+	d := initial
+	for i := 1; i < retry && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+// CreateWithRetry creates a run and waits for it. When it fails with a retryable
+// last_error code, the run is re-created on the same thread with the same request
+// after a backoff, up to the policy's attempt cap. AdditionalMessages are only sent
+// with the first attempt, since its run already added them to the thread. Retries carry
+// the first run's ID and their attempt number in metadata, unless that would exceed
+// the metadata key limit. A run that ends failed after the last attempt
+// is returned without an error; check RetryResult.Run.Status.
+func (s *Service) CreateWithRetry(ctx context.Context, threadID string, req *CreateRunRequest, policy *RetryPolicy) (*RetryResult, error) {
+	if policy == nil {
+		policy = &RetryPolicy{}
+	}
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	wait := policy.Wait
+	if wait == nil {
+		watcher := NewWatcher(s)
+		wait = func(ctx context.Context, run *Run) (*Run, error) {
+			return watcher.Watch(ctx, run.ThreadID, run.ID)
+		}
+	}
+
+	result := &RetryResult{}
+	var firstID string
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		attemptReq := *req
+		if attempt > 1 {
+			// The first run already added these messages to the thread
+			attemptReq.AdditionalMessages = nil
+			attemptReq.Metadata = retryMetadata(req.Metadata, firstID, attempt)
+		}
+
+		run, err := s.Create(threadID, &attemptReq)
+		if err != nil {
+			return result, err
+		}
+		if firstID == "" {
+			firstID = run.ID
+		}
+
+		run, err = wait(ctx, run)
+		if run != nil {
+			result.Run = run
+			result.Attempts = append(result.Attempts, run)
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if attempt == maxAttempts || !policy.IsRetryable(run) {
+			return result, nil
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return result, nil
+}
+
+// retryMetadata tags a retry with the first run's ID and its attempt number,
+// leaving the metadata unchanged when the tags would not fit
+func retryMetadata(metadata types.Metadata, firstID string, attempt int) types.Metadata {
+	keys := len(metadata)
+	for _, k := range []string{MetadataRetryOf, MetadataRetryAttempt} {
+		if _, ok := metadata[k]; !ok {
+			keys++
+		}
+	}
+	if keys > types.MaxMetadataKeys {
+		return metadata
+	}
+
+	m := make(types.Metadata, keys)
+	for k, v := range metadata {
+		m[k] = v
+	}
+	m[MetadataRetryOf] = firstID
+	m[MetadataRetryAttempt] = strconv.Itoa(attempt)
+	return m
+}
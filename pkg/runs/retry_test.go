@@ -0,0 +1,124 @@
+package runs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+func TestCreateWithRetry(t *testing.T) {
+	var created []CreateRunRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var req CreateRunRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			created = append(created, req)
+			runID := "run_" + string(rune('0'+len(created)))
+			json.NewEncoder(w).Encode(Run{ID: runID, ThreadID: "thread_123", Status: StatusQueued})
+			return
+		}
+
+		runID := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		run := Run{ID: runID, ThreadID: "thread_123", Status: StatusCompleted}
+		if runID != "run_3" {
+			run.Status = StatusFailed
+			run.LastError = &ErrorObject{Code: "server_error", Message: "Something went wrong"}
+		}
+		json.NewEncoder(w).Encode(run)
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	service := New(c)
+	service.PollInterval = time.Millisecond
+
+	result, err := service.CreateWithRetry(context.Background(), "thread_123", &CreateRunRequest{
+		AssistantID:        "asst_123",
+		Metadata:           map[string]interface{}{"customer_id": "cus_123"},
+		AdditionalMessages: []Message{{Role: "user", Content: "hello"}},
+	}, &RetryPolicy{InitialBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Run.Status != StatusCompleted {
+		t.Errorf("Expected final status completed, got %s", result.Run.Status)
+	}
+	if len(result.Attempts) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(result.Attempts))
+	}
+
+	if _, ok := created[0].Metadata[MetadataRetryOf]; ok {
+		t.Error("Expected first attempt to have no retry metadata")
+	}
+	if len(created[0].AdditionalMessages) != 1 {
+		t.Error("Expected first attempt to add the additional messages")
+	}
+	for i, req := range created[1:] {
+		if req.Metadata[MetadataRetryOf] != "run_1" {
+			t.Errorf("Attempt %d: expected retry_of run_1, got %v", i+2, req.Metadata[MetadataRetryOf])
+		}
+		if req.Metadata["customer_id"] != "cus_123" {
+			t.Errorf("Attempt %d: expected original metadata to be kept", i+2)
+		}
+		if len(req.AdditionalMessages) != 0 {
+			t.Errorf("Attempt %d: expected additional messages not to be sent again", i+2)
+		}
+	}
+	if created[2].Metadata[MetadataRetryAttempt] != "3" {
+		t.Errorf("Expected retry_attempt 3, got %v", created[2].Metadata[MetadataRetryAttempt])
+	}
+}
+
+func TestRetryMetadataLimit(t *testing.T) {
+	metadata := types.Metadata{}
+	for i := 0; i < types.MaxMetadataKeys-1; i++ {
+		metadata[fmt.Sprintf("key%d", i)] = "v"
+	}
+	if m := retryMetadata(metadata, "run_1", 2); len(m) != len(metadata) {
+		t.Errorf("Expected retry tags to be skipped when they would exceed the key limit, got %d keys", len(m))
+	}
+
+	delete(metadata, "key0")
+	if m := retryMetadata(metadata, "run_1", 2); len(m) != types.MaxMetadataKeys || m[MetadataRetryOf] != "run_1" {
+		t.Errorf("Expected retry tags to be added when they fit, got %v", m)
+	}
+	if m := retryMetadata(types.Metadata{MetadataRetryOf: "run_0", MetadataRetryAttempt: "2"}, "run_1", 3); m[MetadataRetryAttempt] != "3" {
+		t.Errorf("Expected existing retry tags to be replaced, got %v", m)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	if policy.IsRetryable(&Run{Status: StatusFailed, LastError: &ErrorObject{Code: "invalid_prompt"}}) {
+		t.Error("Expected invalid_prompt to not be retryable")
+	}
+	if !policy.IsRetryable(&Run{Status: StatusFailed, LastError: &ErrorObject{Code: "rate_limit_exceeded"}}) {
+		t.Error("Expected rate_limit_exceeded to be retryable")
+	}
+	if policy.IsRetryable(&Run{Status: StatusCompleted}) {
+		t.Error("Expected completed run to not be retryable")
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("Retry %d: expected backoff %s, got %s", i+1, want, got)
+		}
+	}
+}
@@ -7,41 +7,46 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/threads"
 	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
 )
 
 // Run represents an execution run on a thread
 type Run struct {
-	ID                  string              `json:"id"`
-	Object              string              `json:"object"`
-	CreatedAt           int64               `json:"created_at"`
-	ThreadID            string              `json:"thread_id"`
-	AssistantID         string              `json:"assistant_id"`
-	Status              string              `json:"status"`
-	RequiredAction      *RequiredAction     `json:"required_action,omitempty"`
-	LastError           *ErrorObject        `json:"last_error,omitempty"`
-	ExpiresAt           *int64              `json:"expires_at,omitempty"`
-	StartedAt           *int64              `json:"started_at,omitempty"`
-	CancelledAt         *int64              `json:"cancelled_at,omitempty"`
-	FailedAt            *int64              `json:"failed_at,omitempty"`
-	CompletedAt         *int64              `json:"completed_at,omitempty"`
-	Model               string              `json:"model"`
-	Instructions        *string             `json:"instructions,omitempty"`
-	Tools               []Tool              `json:"tools"`
-	ToolResources       *ToolResources      `json:"tool_resources,omitempty"`
-	Metadata            types.Metadata      `json:"metadata,omitempty"`
-	Usage               *Usage              `json:"usage,omitempty"`
-	Temperature         *float64            `json:"temperature,omitempty"`
-	TopP                *float64            `json:"top_p,omitempty"`
-	MaxPromptTokens     *int                `json:"max_prompt_tokens,omitempty"`
-	MaxCompletionTokens *int                `json:"max_completion_tokens,omitempty"`
-	TruncationStrategy  *TruncationStrategy `json:"truncation_strategy,omitempty"`
-	ResponseFormat      interface{}         `json:"response_format,omitempty"`
-	ToolChoice          interface{}         `json:"tool_choice,omitempty"`
-	ParallelToolCalls   bool                `json:"parallel_tool_calls"`
+	ID                  string                `json:"id"`
+	Object              string                `json:"object"`
+	CreatedAt           int64                 `json:"created_at"`
+	ThreadID            string                `json:"thread_id"`
+	AssistantID         string                `json:"assistant_id"`
+	Status              RunStatus             `json:"status"`
+	RequiredAction      *RequiredAction       `json:"required_action,omitempty"`
+	LastError           *ErrorObject          `json:"last_error,omitempty"`
+	IncompleteDetails   *IncompleteDetails    `json:"incomplete_details,omitempty"`
+	ExpiresAt           *int64                `json:"expires_at,omitempty"`
+	StartedAt           *int64                `json:"started_at,omitempty"`
+	CancelledAt         *int64                `json:"cancelled_at,omitempty"`
+	FailedAt            *int64                `json:"failed_at,omitempty"`
+	CompletedAt         *int64                `json:"completed_at,omitempty"`
+	Model               string                `json:"model"`
+	Instructions        *string               `json:"instructions,omitempty"`
+	Tools               []Tool                `json:"tools"`
+	ToolResources       *ToolResources        `json:"tool_resources,omitempty"`
+	Metadata            types.Metadata        `json:"metadata,omitempty"`
+	Usage               *Usage                `json:"usage,omitempty"`
+	Temperature         *float64              `json:"temperature,omitempty"`
+	TopP                *float64              `json:"top_p,omitempty"`
+	MaxPromptTokens     *int                  `json:"max_prompt_tokens,omitempty"`
+	MaxCompletionTokens *int                  `json:"max_completion_tokens,omitempty"`
+	TruncationStrategy  *TruncationStrategy   `json:"truncation_strategy,omitempty"`
+	ResponseFormat      *types.ResponseFormat `json:"response_format,omitempty"`
+	ToolChoice          interface{}           `json:"tool_choice,omitempty"`
+	ParallelToolCalls   bool                  `json:"parallel_tool_calls"`
 }
 
 // RequiredAction represents an action required to continue the run
@@ -75,25 +80,29 @@ type ErrorObject struct {
 	Message string `json:"message"`
 }
 
+// IncompleteDetails explains why a run ended incomplete
+type IncompleteDetails struct {
+	Reason string `json:"reason"`
+}
+
 // Usage represents the token usage for the run
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens        int                  `json:"prompt_tokens"`
+	CompletionTokens    int                  `json:"completion_tokens"`
+	TotalTokens         int                  `json:"total_tokens"`
+	PromptTokensDetails *PromptTokensDetails `json:"prompt_tokens_details,omitempty"`
 }
 
-// Tool represents a tool that can be used by the assistant
-type Tool struct {
-	Type     string        `json:"type"`
-	Function *FunctionTool `json:"function,omitempty"`
+// PromptTokensDetails breaks down the prompt tokens of a run
+type PromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
 }
 
+// Tool represents a tool that can be used by the assistant
+type Tool = types.Tool
+
 // FunctionTool represents a function tool
-type FunctionTool struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Parameters  any    `json:"parameters"`
-}
+type FunctionTool = types.FunctionTool
 
 // ToolResources represents resources available to tools
 type ToolResources struct {
@@ -119,43 +128,60 @@ type TruncationStrategy struct {
 
 // CreateRunRequest represents the request to create a run
 type CreateRunRequest struct {
-	AssistantID            string              `json:"assistant_id"`
-	Model                  *string             `json:"model,omitempty"`
-	Instructions           *string             `json:"instructions,omitempty"`
-	AdditionalInstructions *string             `json:"additional_instructions,omitempty"`
-	Tools                  []Tool              `json:"tools,omitempty"`
-	ToolResources          *ToolResources      `json:"tool_resources,omitempty"`
-	Metadata               types.Metadata      `json:"metadata,omitempty"`
-	Temperature            *float64            `json:"temperature,omitempty"`
-	TopP                   *float64            `json:"top_p,omitempty"`
-	Stream                 bool                `json:"stream,omitempty"`
-	MaxPromptTokens        *int                `json:"max_prompt_tokens,omitempty"`
-	MaxCompletionTokens    *int                `json:"max_completion_tokens,omitempty"`
-	TruncationStrategy     *TruncationStrategy `json:"truncation_strategy,omitempty"`
-	ResponseFormat         interface{}         `json:"response_format,omitempty"`
-	ToolChoice             interface{}         `json:"tool_choice,omitempty"`
-	ParallelToolCalls      *bool               `json:"parallel_tool_calls,omitempty"`
+	AssistantID            string                `json:"assistant_id"`
+	Model                  *string               `json:"model,omitempty"`
+	Instructions           *string               `json:"instructions,omitempty"`
+	AdditionalInstructions *string               `json:"additional_instructions,omitempty"`
+	AdditionalMessages     []Message             `json:"additional_messages,omitempty"`
+	Tools                  []Tool                `json:"tools,omitempty"`
+	ToolResources          *ToolResources        `json:"tool_resources,omitempty"`
+	Metadata               types.Metadata        `json:"metadata,omitempty"`
+	Temperature            *float64              `json:"temperature,omitempty"`
+	TopP                   *float64              `json:"top_p,omitempty"`
+	ReasoningEffort        *string               `json:"reasoning_effort,omitempty"`
+	Stream                 bool                  `json:"stream,omitempty"`
+	MaxPromptTokens        *int                  `json:"max_prompt_tokens,omitempty"`
+	MaxCompletionTokens    *int                  `json:"max_completion_tokens,omitempty"`
+	TruncationStrategy     *TruncationStrategy   `json:"truncation_strategy,omitempty"`
+	ResponseFormat         *types.ResponseFormat `json:"response_format,omitempty"`
+	ToolChoice             interface{}           `json:"tool_choice,omitempty"`
+	ParallelToolCalls      *bool                 `json:"parallel_tool_calls,omitempty"`
+	// Include lists additional fields to include in the run steps, sent as the include[] query parameter,
+	// e.g. "step_details.tool_calls[*].file_search.results[*].content"
+	Include []string `json:"-"`
 }
 
 // CreateThreadAndRunRequest represents the request to create a thread and run
 type CreateThreadAndRunRequest struct {
-	AssistantID string         `json:"assistant_id"`
-	Thread      *ThreadRequest `json:"thread,omitempty"`
-	CreateRunRequest
+	AssistantID         string                `json:"assistant_id"`
+	Thread              *ThreadRequest        `json:"thread,omitempty"`
+	Model               *string               `json:"model,omitempty"`
+	Instructions        *string               `json:"instructions,omitempty"`
+	Tools               []Tool                `json:"tools,omitempty"`
+	ToolResources       *ToolResources        `json:"tool_resources,omitempty"`
+	Metadata            types.Metadata        `json:"metadata,omitempty"`
+	Temperature         *float64              `json:"temperature,omitempty"`
+	TopP                *float64              `json:"top_p,omitempty"`
+	ReasoningEffort     *string               `json:"reasoning_effort,omitempty"`
+	Stream              bool                  `json:"stream,omitempty"`
+	MaxPromptTokens     *int                  `json:"max_prompt_tokens,omitempty"`
+	MaxCompletionTokens *int                  `json:"max_completion_tokens,omitempty"`
+	TruncationStrategy  *TruncationStrategy   `json:"truncation_strategy,omitempty"`
+	ResponseFormat      *types.ResponseFormat `json:"response_format,omitempty"`
+	ToolChoice          interface{}           `json:"tool_choice,omitempty"`
+	ParallelToolCalls   *bool                 `json:"parallel_tool_calls,omitempty"`
 }
 
 // ThreadRequest represents the thread creation part of CreateThreadAndRunRequest
 type ThreadRequest struct {
-	Messages []Message      `json:"messages,omitempty"`
-	Metadata types.Metadata `json:"metadata,omitempty"`
+	Messages      []Message              `json:"messages,omitempty"`
+	ToolResources *threads.ToolResources `json:"tool_resources,omitempty"`
+	Metadata      types.Metadata         `json:"metadata,omitempty"`
 }
 
-// Message represents a message in a thread
-type Message struct {
-	Role     string         `json:"role"`
-	Content  string         `json:"content"`
-	Metadata types.Metadata `json:"metadata,omitempty"`
-}
+// Message represents a message in a thread. It accepts the same content parts
+// and attachments as a message created through the messages package.
+type Message = messages.CreateMessageRequest
 
 // ListRunsResponse represents the response when listing runs
 type ListRunsResponse struct {
@@ -194,6 +220,9 @@ type RunEvent struct {
 
 // Service handles communication with the runs related methods of the OpenAI API
 type Service struct {
+	// PollInterval is the delay between polls of helpers that wait on a run. Defaults to one second.
+	PollInterval time.Duration
+
 	client *client.Client
 }
 
@@ -202,19 +231,59 @@ func New(c *client.Client) *Service {
 	return &Service{client: c}
 }
 
+func (s *Service) pollInterval() time.Duration {
+	if s.PollInterval > 0 {
+		return s.PollInterval
+	}
+	return defaultWatchInterval
+}
+
 // Create creates a new run
 func (s *Service) Create(threadID string, req *CreateRunRequest) (*Run, error) {
-	return s.createRun(fmt.Sprintf("%s/threads/%s/runs", s.client.BaseURL, threadID), req)
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+	req, err := client.CheckModel(s.client, req, req.AssistantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createRun(s.runsURL(threadID, req.Include), req)
 }
 
 // CreateAndStream creates a new run and returns a channel of events
 func (s *Service) CreateAndStream(threadID string, req *CreateRunRequest) (<-chan RunEvent, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+	req, err := client.CheckModel(s.client, req, req.AssistantID)
+	if err != nil {
+		return nil, err
+	}
+
 	req.Stream = true
-	return s.createRunStream(fmt.Sprintf("%s/threads/%s/runs", s.client.BaseURL, threadID), req)
+	return s.createRunStream(s.runsURL(threadID, req.Include), req)
+}
+
+// runsURL builds the URL for creating a run on a thread
+func (s *Service) runsURL(threadID string, include []string) string {
+	runsURL := fmt.Sprintf("%s/threads/%s/runs", s.client.BaseURL, threadID)
+	if len(include) > 0 {
+		runsURL += "?" + url.Values{"include[]": include}.Encode()
+	}
+	return runsURL
 }
 
 // CreateThreadAndRun creates a thread and run in one request
 func (s *Service) CreateThreadAndRun(req *CreateThreadAndRunRequest) (*Run, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+	req, err := client.CheckModel(s.client, req, req.AssistantID)
+	if err != nil {
+		return nil, err
+	}
+
 	return s.createRun(fmt.Sprintf("%s/threads/runs", s.client.BaseURL), req)
 }
 
@@ -227,6 +296,14 @@ func (s *Service) prepareRequest(req *http.Request) {
 
 // CreateThreadAndRunStream creates a thread and run in one request and returns a channel of events
 func (s *Service) CreateThreadAndRunStream(req *CreateThreadAndRunRequest) (<-chan RunEvent, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+	req, err := client.CheckModel(s.client, req, req.AssistantID)
+	if err != nil {
+		return nil, err
+	}
+
 	req.Stream = true
 	return s.createRunStream(fmt.Sprintf("%s/threads/runs", s.client.BaseURL), req)
 }
@@ -249,6 +326,7 @@ func (s *Service) createRun(url string, req interface{}) (*Run, error) {
 		return nil, err
 	}
 
+	s.track(&run)
 	return &run, nil
 }
 
@@ -312,6 +390,7 @@ func (s *Service) createRunStream(url string, req interface{}) (<-chan RunEvent,
 					return
 				}
 
+				s.trackEvent(currentEvent, json.RawMessage(data), true)
 				events <- RunEvent{
 					Event: currentEvent,
 					Data:  json.RawMessage(data),
@@ -325,6 +404,10 @@ func (s *Service) createRunStream(url string, req interface{}) (<-chan RunEvent,
 
 // List returns a list of runs for a thread
 func (s *Service) List(threadID string, params *ListRunsParams) (*ListRunsResponse, error) {
+	if err := s.client.Validate(params); err != nil {
+		return nil, err
+	}
+
 	url := fmt.Sprintf("%s/threads/%s/runs", s.client.BaseURL, threadID)
 	if params != nil {
 		query := make(map[string]string)
@@ -378,11 +461,16 @@ func (s *Service) Get(threadID, runID string) (*Run, error) {
 		return nil, err
 	}
 
+	s.untrackFinished(&run)
 	return &run, nil
 }
 
 // Modify modifies a run
 func (s *Service) Modify(threadID, runID string, metadata types.Metadata) (*Run, error) {
+	if err := s.client.Validate(metadata); err != nil {
+		return nil, err
+	}
+
 	body, err := json.Marshal(map[string]interface{}{
 		"metadata": metadata,
 	})
@@ -402,11 +490,16 @@ func (s *Service) Modify(threadID, runID string, metadata types.Metadata) (*Run,
 		return nil, err
 	}
 
+	s.untrackFinished(&run)
 	return &run, nil
 }
 
 // SubmitToolOutputs submits outputs for tool calls
 func (s *Service) SubmitToolOutputs(threadID, runID string, req *SubmitToolOutputsRequest) (*Run, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -424,11 +517,16 @@ func (s *Service) SubmitToolOutputs(threadID, runID string, req *SubmitToolOutpu
 		return nil, err
 	}
 
+	s.untrackFinished(&run)
 	return &run, nil
 }
 
 // SubmitToolOutputsStream submits outputs for tool calls and returns a channel of events
 func (s *Service) SubmitToolOutputsStream(threadID, runID string, req *SubmitToolOutputsRequest) (<-chan RunEvent, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+
 	req.Stream = true
 	body, err := json.Marshal(req)
 	if err != nil {
@@ -489,6 +587,7 @@ func (s *Service) SubmitToolOutputsStream(threadID, runID string, req *SubmitToo
 					return
 				}
 
+				s.trackEvent(currentEvent, json.RawMessage(data), false)
 				events <- RunEvent{
 					Event: currentEvent,
 					Data:  json.RawMessage(data),
@@ -518,5 +617,6 @@ func (s *Service) Cancel(threadID, runID string) (*Run, error) {
 
 	fmt.Printf("Cancel response run: %+v\n", run)
 
+	s.untrackFinished(&run)
 	return &run, nil
 }
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/threads"
 )
 
 func TestCreateRun(t *testing.T) {
@@ -321,3 +324,183 @@ func TestCancel(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestCreateThreadAndRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/threads/runs" {
+			t.Errorf("Expected path /threads/runs, got %s", r.URL.Path)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		if body["assistant_id"] != "asst_123" {
+			t.Errorf("Expected assistant_id asst_123, got %v", body["assistant_id"])
+		}
+		if body["model"] != "gpt-4o" {
+			t.Errorf("Expected model gpt-4o, got %v", body["model"])
+		}
+
+		thread := body["thread"].(map[string]interface{})
+		message := thread["messages"].([]interface{})[0].(map[string]interface{})
+		content := message["content"].([]interface{})
+		if len(content) != 2 || content[1].(map[string]interface{})["type"] != "image_file" {
+			t.Errorf("Expected multi-part content, got %v", content)
+		}
+		if len(message["attachments"].([]interface{})) != 1 {
+			t.Errorf("Expected one attachment, got %v", message["attachments"])
+		}
+
+		fileSearch := thread["tool_resources"].(map[string]interface{})["file_search"].(map[string]interface{})
+		if _, ok := fileSearch["vector_store_ids"]; ok {
+			t.Errorf("Expected vector_store_ids to be omitted, got %v", fileSearch)
+		}
+		if len(fileSearch["vector_stores"].([]interface{})) != 1 {
+			t.Errorf("Expected one inline vector store, got %v", fileSearch["vector_stores"])
+		}
+
+		json.NewEncoder(w).Encode(Run{ID: "run_123", ThreadID: "thread_123", AssistantID: "asst_123", Status: StatusQueued})
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	service := New(c)
+
+	run, err := service.CreateThreadAndRun(&CreateThreadAndRunRequest{
+		AssistantID: "asst_123",
+		Model:       stringPtr("gpt-4o"),
+		Thread: &ThreadRequest{
+			Messages: []Message{
+				{
+					Role: "user",
+					Content: []messages.ContentPart{
+						messages.TextPart("What is in this image?"),
+						messages.ImageFilePart("file_img", "auto"),
+					},
+					Attachments: []messages.Attachment{
+						{FileID: "file_doc", Tools: []messages.Tool{{Type: "file_search"}}},
+					},
+				},
+			},
+			ToolResources: &threads.ToolResources{
+				FileSearch: &threads.FileSearchResources{
+					VectorStores: []threads.VectorStore{{FileIDs: []string{"file_doc"}}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if run.ThreadID != "thread_123" {
+		t.Errorf("Expected thread ID thread_123, got %s", run.ThreadID)
+	}
+}
+
+func TestCreateRunWithAdditionalMessagesAndInclude(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query()["include[]"]; len(got) != 2 || got[0] != "step_details.tool_calls[*].file_search.results[*].content" || got[1] != "a&b=c" {
+			t.Errorf("Expected include[] query parameter, got %v", r.URL.RawQuery)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if _, ok := body["include"]; ok {
+			t.Error("Expected include to be sent as a query parameter only")
+		}
+
+		additional := body["additional_messages"].([]interface{})
+		if len(additional) != 1 {
+			t.Fatalf("Expected one additional message, got %v", additional)
+		}
+		message := additional[0].(map[string]interface{})
+		if message["role"] != "user" || len(message["attachments"].([]interface{})) != 1 {
+			t.Errorf("Unexpected additional message %v", message)
+		}
+
+		json.NewEncoder(w).Encode(Run{ID: "run_123", ThreadID: "thread_123", Status: StatusQueued})
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	service := New(c)
+
+	_, err := service.Create("thread_123", &CreateRunRequest{
+		AssistantID: "asst_123",
+		AdditionalMessages: []Message{
+			{
+				Role:    "user",
+				Content: []messages.ContentPart{messages.TextPart("What does the contract say about renewals?")},
+				Attachments: []messages.Attachment{
+					{FileID: "file_contract", Tools: []messages.Tool{{Type: "file_search"}}},
+				},
+			},
+		},
+		// The second value is not a real field; it checks that values are escaped
+		Include: []string{"step_details.tool_calls[*].file_search.results[*].content", "a&b=c"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestCreateThreadAndRunValidation(t *testing.T) {
+	maxPrompt := 100
+	effort := "extreme"
+	req := &CreateThreadAndRunRequest{
+		AssistantID:     "asst_123",
+		MaxPromptTokens: &maxPrompt,
+		ReasoningEffort: &effort,
+		Thread: &ThreadRequest{
+			Messages: []Message{
+				{Role: "user", Content: "hello"},
+				{Role: "system", Content: []messages.ContentPart{messages.TextPart("")}},
+			},
+			ToolResources: &threads.ToolResources{
+				FileSearch: &threads.FileSearchResources{VectorStoreIDs: []string{"vs_1", "vs_2"}},
+			},
+		},
+		TruncationStrategy: &TruncationStrategy{Type: "last_messages"},
+	}
+
+	err := req.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, path := range []string{
+		"thread.messages[1].role",
+		"thread.messages[1].content[0].text",
+		"thread.tool_resources.file_search.vector_store_ids",
+		"reasoning_effort",
+		"max_prompt_tokens",
+		"truncation_strategy.last_messages",
+	} {
+		if !strings.Contains(err.Error(), path+":") {
+			t.Errorf("Expected error at %s, got %v", path, err)
+		}
+	}
+	if strings.Contains(err.Error(), "messages[0]") {
+		t.Errorf("Expected first message to be valid, got %v", err)
+	}
+
+	outputs := &SubmitToolOutputsRequest{ToolOutputs: []ToolOutput{{ToolCallID: "call_1"}, {ToolCallID: "call_1"}, {}}}
+	err = outputs.Validate()
+	if err == nil || !strings.Contains(err.Error(), "tool_outputs[1].tool_call_id: duplicates") || !strings.Contains(err.Error(), "tool_outputs[2].tool_call_id: is required") {
+		t.Errorf("Expected duplicate and missing tool call IDs, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package runs
+
+// RunStatus represents the status of a run
+type RunStatus string
+
+const (
+	StatusQueued         RunStatus = "queued"
+	StatusInProgress     RunStatus = "in_progress"
+	StatusRequiresAction RunStatus = "requires_action"
+	StatusCancelling     RunStatus = "cancelling"
+	StatusCancelled      RunStatus = "cancelled"
+	StatusFailed         RunStatus = "failed"
+	StatusCompleted      RunStatus = "completed"
+	StatusIncomplete     RunStatus = "incomplete"
+	StatusExpired        RunStatus = "expired"
+)
+
+// IsTerminal reports whether the run has stopped and its status will no longer change
+func (s RunStatus) IsTerminal() bool {
+	switch s {
+	case StatusCancelled, StatusFailed, StatusCompleted, StatusIncomplete, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// NeedsAction reports whether the run is waiting on the caller, e.g. for tool outputs
+func (s RunStatus) NeedsAction() bool {
+	return s == StatusRequiresAction
+}
+
+// IsActive reports whether the run still occupies its thread
+func (s RunStatus) IsActive() bool {
+	switch s {
+	case StatusQueued, StatusInProgress, StatusRequiresAction, StatusCancelling:
+		return true
+	}
+	return false
+}
+
+// IsKnown reports whether the status is one of the documented run states
+func (s RunStatus) IsKnown() bool {
+	return s.IsTerminal() || s.IsActive()
+}
+
+func (s RunStatus) String() string {
+	return string(s)
+}
@@ -0,0 +1,105 @@
+package runs
+
+import (
+	"fmt"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// minTokenLimit is the smallest max_prompt_tokens and max_completion_tokens the API accepts
+const minTokenLimit = 256
+
+// Validate checks the request against the API's limits and returns every problem found
+func (r *CreateRunRequest) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var v types.Validator
+	v.Required("assistant_id", r.AssistantID)
+	for i := range r.AdditionalMessages {
+		v.Nested(fmt.Sprintf("additional_messages[%d]", i), r.AdditionalMessages[i].Validate())
+	}
+	validateRunOptions(&v, r.Tools, r.Metadata, r.Temperature, r.TopP, r.MaxPromptTokens, r.MaxCompletionTokens, r.TruncationStrategy, r.ResponseFormat)
+	if r.ReasoningEffort != nil {
+		v.ReasoningEffort("reasoning_effort", *r.ReasoningEffort)
+	}
+	return v.Err()
+}
+
+// Validate checks the request against the API's limits and returns every problem found
+func (r *CreateThreadAndRunRequest) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var v types.Validator
+	v.Required("assistant_id", r.AssistantID)
+	if r.Thread != nil {
+		for i := range r.Thread.Messages {
+			v.Nested(fmt.Sprintf("thread.messages[%d]", i), r.Thread.Messages[i].Validate())
+		}
+		v.Nested("thread.tool_resources", r.Thread.ToolResources.Validate())
+		v.Metadata("thread.metadata", r.Thread.Metadata)
+	}
+	if r.ReasoningEffort != nil {
+		v.ReasoningEffort("reasoning_effort", *r.ReasoningEffort)
+	}
+	validateRunOptions(&v, r.Tools, r.Metadata, r.Temperature, r.TopP, r.MaxPromptTokens, r.MaxCompletionTokens, r.TruncationStrategy, r.ResponseFormat)
+	return v.Err()
+}
+
+// Validate checks that every output names its tool call
+func (r *SubmitToolOutputsRequest) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var v types.Validator
+	if len(r.ToolOutputs) == 0 {
+		v.Addf("tool_outputs", "is required")
+	}
+	seen := make(map[string]int)
+	for i, out := range r.ToolOutputs {
+		path := fmt.Sprintf("tool_outputs[%d].tool_call_id", i)
+		v.Required(path, out.ToolCallID)
+		if first, ok := seen[out.ToolCallID]; ok && out.ToolCallID != "" {
+			v.Addf(path, "duplicates tool_outputs[%d]", first)
+		} else {
+			seen[out.ToolCallID] = i
+		}
+	}
+	return v.Err()
+}
+
+// Validate checks the list parameters
+func (p *ListRunsParams) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	var v types.Validator
+	v.ListParams(p.Limit, p.Order)
+	return v.Err()
+}
+
+func validateRunOptions(v *types.Validator, tools []Tool, metadata types.Metadata, temperature, topP *float64,
+	maxPromptTokens, maxCompletionTokens *int, truncation *TruncationStrategy, format *types.ResponseFormat) {
+	v.Tools("tools", tools)
+	v.Metadata("metadata", metadata)
+	v.Range("temperature", temperature, 0, 2)
+	v.Range("top_p", topP, 0, 1)
+	if maxPromptTokens != nil && *maxPromptTokens < minTokenLimit {
+		v.Addf("max_prompt_tokens", "must be at least %d, got %d", minTokenLimit, *maxPromptTokens)
+	}
+	if maxCompletionTokens != nil && *maxCompletionTokens < minTokenLimit {
+		v.Addf("max_completion_tokens", "must be at least %d, got %d", minTokenLimit, *maxCompletionTokens)
+	}
+	if truncation != nil {
+		v.OneOf("truncation_strategy.type", truncation.Type, "auto", "last_messages")
+		if truncation.Type == "last_messages" && (truncation.LastMessages == nil || *truncation.LastMessages < 1) {
+			v.Addf("truncation_strategy.last_messages", "must be at least 1 for last_messages")
+		}
+	}
+	v.ResponseFormat("response_format", format)
+}
@@ -0,0 +1,196 @@
+package runs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchInterval = time.Second
+	defaultStuckAfter    = 5 * time.Minute
+	defaultExpiryWarning = time.Minute
+)
+
+// StuckReason describes why a run was flagged as stuck
+type StuckReason string
+
+const (
+	// StuckQueued means the run stayed queued longer than the threshold
+	StuckQueued StuckReason = "queued"
+	// StuckInProgress means the run stayed in progress longer than the threshold
+	StuckInProgress StuckReason = "in_progress"
+	// StuckExpiring means the run is close to expires_at while still waiting on tool outputs
+	StuckExpiring StuckReason = "expiring"
+)
+
+// Transition describes an observed change of a run's status
+type Transition struct {
+	From RunStatus
+	To   RunStatus
+	Run  *Run
+	At   time.Time
+}
+
+// StuckEvent describes a run that is not making progress
+type StuckEvent struct {
+	Reason StuckReason
+	Run    *Run
+	// Duration is how long the run has been in its current status
+	Duration time.Duration
+}
+
+// Watcher polls runs and reports status transitions and stuck runs to registered hooks
+type Watcher struct {
+	// Interval is the delay between polls. Defaults to one second.
+	Interval time.Duration
+	// StuckAfter is how long a run may stay queued or in progress before it is flagged.
+	StuckAfter time.Duration
+	// ExpiryWarning flags runs waiting on tool outputs this close to expires_at.
+	ExpiryWarning time.Duration
+
+	service *Service
+	now     func() time.Time
+
+	mu           sync.Mutex
+	transitions  []func(Transition)
+	stuck        []func(StuckEvent)
+	observations map[string]*observation
+}
+
+type observation struct {
+	status  RunStatus
+	since   time.Time
+	flagged bool
+}
+
+// NewWatcher creates a new watcher that polls runs through the service
+func NewWatcher(s *Service) *Watcher {
+	interval := defaultWatchInterval
+	if s != nil {
+		interval = s.pollInterval()
+	}
+
+	return &Watcher{
+		Interval:      interval,
+		StuckAfter:    defaultStuckAfter,
+		ExpiryWarning: defaultExpiryWarning,
+		service:       s,
+		now:           time.Now,
+		observations:  make(map[string]*observation),
+	}
+}
+
+// OnTransition registers a hook called for every observed status change
+func (w *Watcher) OnTransition(hook func(Transition)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.transitions = append(w.transitions, hook)
+}
+
+// OnStuck registers a hook called once each time a run is flagged as stuck
+func (w *Watcher) OnStuck(hook func(StuckEvent)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stuck = append(w.stuck, hook)
+}
+
+// Watch polls the run until it reaches a terminal status or needs action and returns the last snapshot
+func (w *Watcher) Watch(ctx context.Context, threadID, runID string) (*Run, error) {
+	return w.WatchUntil(ctx, threadID, runID, func(run *Run) bool {
+		return run.Status.IsTerminal() || run.Status.NeedsAction()
+	})
+}
+
+// WatchUntil polls the run until done returns true and returns the last snapshot
+func (w *Watcher) WatchUntil(ctx context.Context, threadID, runID string, done func(*Run) bool) (*Run, error) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	for {
+		run, err := w.service.Get(threadID, runID)
+		if err != nil {
+			return nil, err
+		}
+
+		w.Observe(run)
+		if done(run) {
+			return run, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return run, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Observe records a run snapshot obtained elsewhere, such as from a stream, and fires any hooks
+func (w *Watcher) Observe(run *Run) {
+	now := w.now()
+
+	w.mu.Lock()
+	obs, seen := w.observations[run.ID]
+	var transition *Transition
+	if !seen || obs.status != run.Status {
+		from := RunStatus("")
+		if seen {
+			from = obs.status
+		}
+		transition = &Transition{From: from, To: run.Status, Run: run, At: now}
+		obs = &observation{status: run.Status, since: now}
+		w.observations[run.ID] = obs
+	}
+
+	var event *StuckEvent
+	if !obs.flagged {
+		if reason, ok := w.stuckReason(run, obs, now); ok {
+			obs.flagged = true
+			event = &StuckEvent{Reason: reason, Run: run, Duration: now.Sub(obs.since)}
+		}
+	}
+
+	if run.Status.IsTerminal() {
+		delete(w.observations, run.ID)
+	}
+
+	transitionHooks := append([]func(Transition){}, w.transitions...)
+	stuckHooks := append([]func(StuckEvent){}, w.stuck...)
+	w.mu.Unlock()
+
+	if transition != nil {
+		for _, hook := range transitionHooks {
+			hook(*transition)
+		}
+	}
+	if event != nil {
+		for _, hook := range stuckHooks {
+			hook(*event)
+		}
+	}
+}
+
+func (w *Watcher) stuckReason(run *Run, obs *observation, now time.Time) (StuckReason, bool) {
+	switch run.Status {
+	case StatusQueued:
+		if w.StuckAfter > 0 && now.Sub(obs.since) >= w.StuckAfter {
+			return StuckQueued, true
+		}
+	case StatusInProgress:
+		if w.StuckAfter > 0 && now.Sub(obs.since) >= w.StuckAfter {
+			return StuckInProgress, true
+		}
+	case StatusRequiresAction:
+		if w.ExpiryWarning > 0 && run.ExpiresAt != nil {
+			if time.Unix(*run.ExpiresAt, 0).Sub(now) <= w.ExpiryWarning {
+				return StuckExpiring, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,131 @@
+package runs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+)
+
+func TestRunStatus(t *testing.T) {
+	tests := []struct {
+		status   RunStatus
+		terminal bool
+		action   bool
+		active   bool
+	}{
+		{StatusQueued, false, false, true},
+		{StatusInProgress, false, false, true},
+		{StatusRequiresAction, false, true, true},
+		{StatusCancelling, false, false, true},
+		{StatusCancelled, true, false, false},
+		{StatusFailed, true, false, false},
+		{StatusCompleted, true, false, false},
+		{StatusIncomplete, true, false, false},
+		{StatusExpired, true, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.IsTerminal(); got != tt.terminal {
+			t.Errorf("%s: expected IsTerminal %v, got %v", tt.status, tt.terminal, got)
+		}
+		if got := tt.status.NeedsAction(); got != tt.action {
+			t.Errorf("%s: expected NeedsAction %v, got %v", tt.status, tt.action, got)
+		}
+		if got := tt.status.IsActive(); got != tt.active {
+			t.Errorf("%s: expected IsActive %v, got %v", tt.status, tt.active, got)
+		}
+		if !tt.status.IsKnown() {
+			t.Errorf("%s: expected status to be known", tt.status)
+		}
+	}
+
+	if RunStatus("bogus").IsKnown() {
+		t.Error("Expected unknown status to not be known")
+	}
+}
+
+func TestWatcherWatch(t *testing.T) {
+	statuses := []RunStatus{StatusQueued, StatusQueued, StatusInProgress, StatusCompleted}
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[calls]
+		calls++
+		json.NewEncoder(w).Encode(Run{ID: "run_123", ThreadID: "thread_123", Status: status})
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	watcher := NewWatcher(New(c))
+	watcher.Interval = time.Millisecond
+
+	var transitions []Transition
+	watcher.OnTransition(func(tr Transition) {
+		transitions = append(transitions, tr)
+	})
+
+	run, err := watcher.Watch(context.Background(), "thread_123", "run_123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Status != StatusCompleted {
+		t.Errorf("Expected status completed, got %s", run.Status)
+	}
+
+	expected := [][2]RunStatus{
+		{"", StatusQueued},
+		{StatusQueued, StatusInProgress},
+		{StatusInProgress, StatusCompleted},
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected %d transitions, got %d", len(expected), len(transitions))
+	}
+	for i, tr := range transitions {
+		if tr.From != expected[i][0] || tr.To != expected[i][1] {
+			t.Errorf("Transition %d: expected %s -> %s, got %s -> %s", i, expected[i][0], expected[i][1], tr.From, tr.To)
+		}
+	}
+}
+
+func TestWatcherStuck(t *testing.T) {
+	now := time.Unix(1699000000, 0)
+
+	watcher := NewWatcher(nil)
+	watcher.StuckAfter = time.Minute
+	watcher.ExpiryWarning = 30 * time.Second
+	watcher.now = func() time.Time { return now }
+
+	var events []StuckEvent
+	watcher.OnStuck(func(ev StuckEvent) {
+		events = append(events, ev)
+	})
+
+	watcher.Observe(&Run{ID: "run_1", Status: StatusQueued})
+	now = now.Add(2 * time.Minute)
+	watcher.Observe(&Run{ID: "run_1", Status: StatusQueued})
+	watcher.Observe(&Run{ID: "run_1", Status: StatusQueued})
+
+	if len(events) != 1 || events[0].Reason != StuckQueued {
+		t.Fatalf("Expected one queued stuck event, got %+v", events)
+	}
+	if events[0].Duration != 2*time.Minute {
+		t.Errorf("Expected duration 2m, got %s", events[0].Duration)
+	}
+
+	expiresAt := now.Add(10 * time.Second).Unix()
+	watcher.Observe(&Run{ID: "run_2", Status: StatusRequiresAction, ExpiresAt: &expiresAt})
+
+	if len(events) != 2 || events[1].Reason != StuckExpiring {
+		t.Fatalf("Expected an expiring stuck event, got %+v", events)
+	}
+}
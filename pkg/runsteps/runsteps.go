@@ -44,9 +44,10 @@ type MessageCreation struct {
 
 // ToolCall represents a call to a tool within a run step.
 type ToolCall struct {
-	ID       string   `json:"id"`
-	Type     string   `json:"type"`
-	Function Function `json:"function"`
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	Function   Function    `json:"function"`
+	FileSearch *FileSearch `json:"file_search,omitempty"`
 }
 
 // Function represents the function call details within a tool call.
@@ -56,6 +57,33 @@ type Function struct {
 	Output    string `json:"output"`
 }
 
+// FileSearch holds the details of a file_search tool call.
+type FileSearch struct {
+	RankingOptions *RankingOptions    `json:"ranking_options,omitempty"`
+	Results        []FileSearchResult `json:"results,omitempty"`
+}
+
+// RankingOptions holds the ranking settings used for a file search.
+type RankingOptions struct {
+	Ranker         string  `json:"ranker"`
+	ScoreThreshold float64 `json:"score_threshold"`
+}
+
+// FileSearchResult is a file chunk found by a file search. Content is only returned
+// when "step_details.tool_calls[*].file_search.results[*].content" is included.
+type FileSearchResult struct {
+	FileID   string              `json:"file_id"`
+	FileName string              `json:"file_name"`
+	Score    float64             `json:"score"`
+	Content  []FileSearchContent `json:"content,omitempty"`
+}
+
+// FileSearchContent is a part of the content of a file search result.
+type FileSearchContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
 // ErrorObject represents an error that occurred during the run step.
 type ErrorObject struct {
 	Code    string `json:"code"`
@@ -64,9 +92,15 @@ type ErrorObject struct {
 
 // Usage holds the token usage statistics for the run step.
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens        int                  `json:"prompt_tokens"`
+	CompletionTokens    int                  `json:"completion_tokens"`
+	TotalTokens         int                  `json:"total_tokens"`
+	PromptTokensDetails *PromptTokensDetails `json:"prompt_tokens_details,omitempty"`
+}
+
+// PromptTokensDetails breaks down the prompt tokens of a run step.
+type PromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
 }
 
 // ListRunStepsResponse represents the response from listing run steps.
@@ -266,6 +266,57 @@ func TestGetRunStep(t *testing.T) {
 	}
 }
 
+func TestGetRunStepFileSearchResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"id": "step_abc123",
+			"status": "completed",
+			"step_details": {
+				"type": "tool_calls",
+				"tool_calls": [{
+					"id": "call_abc123",
+					"type": "file_search",
+					"file_search": {
+						"ranking_options": {"ranker": "default_2024_08_21", "score_threshold": 0.0},
+						"results": [{
+							"file_id": "file_abc123",
+							"file_name": "contract.pdf",
+							"score": 0.82,
+							"content": [{"type": "text", "text": "Renewal is automatic."}]
+						}]
+					}
+				}]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	runStep, err := New(c).Get("thread_abc123", "run_abc123", "step_abc123", &GetRunStepParams{
+		Include: []string{"step_details.tool_calls[*].file_search.results[*].content"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	fs := runStep.StepDetails.ToolCalls[0].FileSearch
+	if fs == nil || len(fs.Results) != 1 {
+		t.Fatalf("Expected one file search result, got %+v", fs)
+	}
+	result := fs.Results[0]
+	if result.FileName != "contract.pdf" || result.Score != 0.82 || len(result.Content) != 1 || result.Content[0].Text != "Renewal is automatic." {
+		t.Errorf("Expected the result content to be decoded, got %+v", result)
+	}
+	if fs.RankingOptions == nil || fs.RankingOptions.Ranker != "default_2024_08_21" {
+		t.Errorf("Expected ranking options, got %+v", fs.RankingOptions)
+	}
+}
+
 // Helper functions to create pointers for test parameters
 func intPtr(i int) *int {
 	return &i
@@ -30,9 +30,24 @@ type CodeInterpreterResources struct {
 	FileIDs []string `json:"file_ids"`
 }
 
-// FileSearchResources represents resources for the file search tool
+// FileSearchResources represents resources for the file search tool.
+// VectorStores creates new vector stores inline and can only be used when creating a thread.
 type FileSearchResources struct {
-	VectorStoreIDs []string `json:"vector_store_ids"`
+	VectorStoreIDs []string      `json:"vector_store_ids,omitempty"`
+	VectorStores   []VectorStore `json:"vector_stores,omitempty"`
+}
+
+// MarshalJSON omits vector_store_ids when nil but sends an empty list,
+// which detaches every vector store when modifying a thread
+func (r FileSearchResources) MarshalJSON() ([]byte, error) {
+	fields := struct {
+		VectorStoreIDs *[]string     `json:"vector_store_ids,omitempty"`
+		VectorStores   []VectorStore `json:"vector_stores,omitempty"`
+	}{VectorStores: r.VectorStores}
+	if r.VectorStoreIDs != nil {
+		fields.VectorStoreIDs = &r.VectorStoreIDs
+	}
+	return json.Marshal(fields)
 }
 
 // Message represents a message in a thread
@@ -93,6 +108,18 @@ type CreateThreadRequest struct {
 	Metadata      types.Metadata `json:"metadata,omitempty"`
 }
 
+// ModifyThreadRequest represents the request to modify a thread.
+// Unset fields are left unchanged; types.Null clears a field.
+type ModifyThreadRequest struct {
+	ToolResources types.Optional[ToolResources]  `json:"tool_resources"`
+	Metadata      types.Optional[types.Metadata] `json:"metadata"`
+}
+
+// MarshalJSON encodes only the fields that are set or null
+func (r ModifyThreadRequest) MarshalJSON() ([]byte, error) {
+	return types.MarshalRequest(r)
+}
+
 // DeleteThreadResponse represents the response when deleting a thread
 type DeleteThreadResponse struct {
 	ID      string `json:"id"`
@@ -112,6 +139,10 @@ func New(c *client.Client) *Service {
 
 // Create creates a new thread
 func (s *Service) Create(req *CreateThreadRequest) (*Thread, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -142,7 +173,7 @@ func (s *Service) Get(threadID string) (*Thread, error) {
 	req.Header.Set("OpenAI-Beta", "assistants=v2")
 
 	var thread Thread
-	if err := s.client.SendRequest(req, &thread); err != nil {
+	if err := s.client.SendCachedRequest(req, client.ResourceThread, threadID, &thread); err != nil {
 		return nil, err
 	}
 
@@ -150,24 +181,27 @@ func (s *Service) Get(threadID string) (*Thread, error) {
 }
 
 // Modify modifies a thread
-func (s *Service) Modify(threadID string, toolResources *ToolResources, metadata types.Metadata) (*Thread, error) {
-	body, err := json.Marshal(map[string]interface{}{
-		"tool_resources": toolResources,
-		"metadata":       metadata,
-	})
+func (s *Service) Modify(threadID string, req *ModifyThreadRequest) (*Thread, error) {
+	if err := s.client.Validate(req); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/threads/%s", s.client.BaseURL, threadID), bytes.NewReader(body))
+	httpReq, err := http.NewRequest("POST", fmt.Sprintf("%s/threads/%s", s.client.BaseURL, threadID), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
+	httpReq.Header.Set("OpenAI-Beta", "assistants=v2")
 
 	var thread Thread
-	if err := s.client.SendRequest(req, &thread); err != nil {
+	err = s.client.SendRequest(httpReq, &thread)
+	s.client.Invalidate(client.ResourceThread, threadID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -184,7 +218,9 @@ func (s *Service) Delete(threadID string) (*DeleteThreadResponse, error) {
 	req.Header.Set("OpenAI-Beta", "assistants=v2")
 
 	var response DeleteThreadResponse
-	if err := s.client.SendRequest(req, &response); err != nil {
+	err = s.client.SendRequest(req, &response)
+	s.client.Invalidate(client.ResourceThread, threadID)
+	if err != nil {
 		return nil, err
 	}
 
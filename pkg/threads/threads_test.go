@@ -2,8 +2,10 @@ package threads
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/greenstorm5417/openai-assistants-go/client"
@@ -134,7 +136,7 @@ func TestModifyThread(t *testing.T) {
 		"user":     "abc123",
 	}
 
-	thread, err := service.Modify("thread_123", nil, metadata)
+	thread, err := service.Modify("thread_123", &ModifyThreadRequest{Metadata: types.Set(metadata)})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -148,6 +150,38 @@ func TestModifyThread(t *testing.T) {
 	}
 }
 
+func TestModifyThreadDetachVectorStores(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := `{"tool_resources":{"file_search":{"vector_store_ids":[]}}}`
+		if string(body) != expected {
+			t.Errorf("Expected body %s, got %s", expected, body)
+		}
+		json.NewEncoder(w).Encode(Thread{ID: "thread_123"})
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	service := New(c)
+
+	_, err := service.Modify("thread_123", &ModifyThreadRequest{
+		ToolResources: types.Set(ToolResources{FileSearch: &FileSearchResources{VectorStoreIDs: []string{}}}),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, _ := json.Marshal(ModifyThreadRequest{Metadata: types.Null[types.Metadata]()})
+	if string(data) != `{"metadata":null}` {
+		t.Errorf("Expected metadata to be cleared, got %s", data)
+	}
+}
+
 func TestDeleteThread(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "DELETE" {
@@ -248,3 +282,23 @@ func TestMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateThreadValidation(t *testing.T) {
+	req := &CreateThreadRequest{
+		Messages: []Message{{Role: "user"}},
+		ToolResources: &ToolResources{FileSearch: &FileSearchResources{
+			VectorStoreIDs: []string{"vs_1"},
+			VectorStores:   []VectorStore{{FileIDs: []string{"file_1"}}},
+		}},
+	}
+
+	err := req.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, path := range []string{"messages[0].content", "tool_resources.file_search"} {
+		if !strings.Contains(err.Error(), path+":") {
+			t.Errorf("Expected error at %s, got %v", path, err)
+		}
+	}
+}
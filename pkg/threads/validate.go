@@ -0,0 +1,79 @@
+package threads
+
+import (
+	"fmt"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// Validate checks the request against the API's limits and returns every problem found
+func (r *CreateThreadRequest) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var v types.Validator
+	for i := range r.Messages {
+		v.Nested(fmt.Sprintf("messages[%d]", i), r.Messages[i].Validate())
+	}
+	validateToolResources(&v, r.ToolResources)
+	v.Metadata("metadata", r.Metadata)
+	return v.Err()
+}
+
+// Validate checks the fields that are set against the API's limits and returns every problem found
+func (r *ModifyThreadRequest) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var v types.Validator
+	if resources, ok := r.ToolResources.Value(); ok {
+		validateToolResources(&v, &resources)
+		if resources.FileSearch != nil && len(resources.FileSearch.VectorStores) > 0 {
+			v.Addf("tool_resources.file_search.vector_stores", "can only be used when creating a thread")
+		}
+	}
+	if metadata, ok := r.Metadata.Value(); ok {
+		v.Metadata("metadata", metadata)
+	}
+	return v.Err()
+}
+
+// Validate checks the message role and content
+func (m *Message) Validate() error {
+	var v types.Validator
+	v.Required("role", m.Role)
+	v.OneOf("role", m.Role, "user", "assistant")
+	v.Required("content", m.Content)
+	v.Metadata("metadata", m.Metadata)
+	return v.Err()
+}
+
+// Validate checks the tool resources against the API's limits
+func (r *ToolResources) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var v types.Validator
+	if r.CodeInterpreter != nil && len(r.CodeInterpreter.FileIDs) > 20 {
+		v.Addf("code_interpreter.file_ids", "must have at most 20 files, got %d", len(r.CodeInterpreter.FileIDs))
+	}
+	if fs := r.FileSearch; fs != nil {
+		if len(fs.VectorStoreIDs) > 1 {
+			v.Addf("file_search.vector_store_ids", "must have at most 1 vector store, got %d", len(fs.VectorStoreIDs))
+		}
+		if len(fs.VectorStores) > 1 {
+			v.Addf("file_search.vector_stores", "must have at most 1 vector store, got %d", len(fs.VectorStores))
+		}
+		if len(fs.VectorStoreIDs) > 0 && len(fs.VectorStores) > 0 {
+			v.Addf("file_search", "set vector_store_ids or vector_stores, not both")
+		}
+	}
+	return v.Err()
+}
+
+func validateToolResources(v *types.Validator, r *ToolResources) {
+	v.Nested("tool_resources", r.Validate())
+}
@@ -0,0 +1,196 @@
+package tokens
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultPattern splits text into pieces before byte pair merging. It follows the
+// cl100k_base pattern; the negative lookahead of the original, which Go regular
+// expressions do not support, is emulated while splitting.
+const DefaultPattern = `(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`
+
+// Tokenizer converts text to tokens
+type Tokenizer interface {
+	// Encode returns the token IDs of text
+	Encode(text string) []int
+	// Count returns the number of tokens in text
+	Count(text string) int
+}
+
+// BPE is a byte pair encoding tokenizer using a tiktoken-style vocabulary
+type BPE struct {
+	ranks   map[string]int
+	pattern *regexp.Regexp
+}
+
+// LoadBPE reads a vocabulary in the tiktoken format, one base64 encoded token and
+// its rank per line, and creates a tokenizer splitting text with DefaultPattern
+func LoadBPE(r io.Reader) (*BPE, error) {
+	return LoadBPEWithPattern(r, DefaultPattern)
+}
+
+// LoadBPEWithPattern is like LoadBPE but splits text with a custom pattern
+func LoadBPEWithPattern(r io.Reader, pattern string) (*BPE, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid vocabulary line %d", line)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid token on vocabulary line %d: %w", line, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank on vocabulary line %d: %w", line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("vocabulary is empty")
+	}
+
+	return &BPE{ranks: ranks, pattern: re}, nil
+}
+
+// LoadBPEFile reads a vocabulary from a local file
+func LoadBPEFile(path string) (*BPE, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadBPE(f)
+}
+
+// LoadBPEFS reads a vocabulary from a file system, such as an embed.FS
+func LoadBPEFS(fsys fs.FS, name string) (*BPE, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadBPE(f)
+}
+
+// Encode returns the token IDs of text. Bytes missing from the vocabulary are encoded as -1.
+func (b *BPE) Encode(text string) []int {
+	var ids []int
+	for _, piece := range b.split(text) {
+		ids = append(ids, b.encodePiece(piece)...)
+	}
+	return ids
+}
+
+// Count returns the number of tokens in text
+func (b *BPE) Count(text string) int {
+	return len(b.Encode(text))
+}
+
+// split breaks text into pieces. A run of whitespace followed by other text gives up
+// its last character to the next piece, as the \s+(?!\S) rule of the original pattern does.
+func (b *BPE) split(text string) []string {
+	var pieces []string
+	for pos := 0; pos < len(text); {
+		loc := b.pattern.FindStringIndex(text[pos:])
+		if loc == nil || loc[1] == 0 {
+			// No match at all; keep the remainder as one piece
+			pieces = append(pieces, text[pos:])
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		if start > pos {
+			pieces = append(pieces, text[pos:start])
+		}
+
+		piece := text[start:end]
+		if end < len(text) && isSpaceRun(piece) {
+			if _, size := utf8.DecodeLastRuneInString(piece); size < len(piece) {
+				end -= size
+				piece = text[start:end]
+			}
+		}
+
+		pieces = append(pieces, piece)
+		pos = end
+	}
+	return pieces
+}
+
+// isSpaceRun reports whether s is whitespace that does not end in a line break
+func isSpaceRun(s string) bool {
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	last, _ := utf8.DecodeLastRuneInString(s)
+	return last != '\n' && last != '\r'
+}
+
+// encodePiece merges the bytes of a piece, always merging the adjacent pair with the lowest rank first
+func (b *BPE) encodePiece(piece string) []int {
+	if rank, ok := b.ranks[piece]; ok {
+		return []int{rank}
+	}
+
+	parts := make([]string, len(piece))
+	for i := 0; i < len(piece); i++ {
+		parts[i] = piece[i : i+1]
+	}
+
+	for len(parts) > 1 {
+		best, bestRank := -1, 0
+		for i := 0; i < len(parts)-1; i++ {
+			rank, ok := b.ranks[parts[i]+parts[i+1]]
+			if ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+
+	ids := make([]int, len(parts))
+	for i, part := range parts {
+		if rank, ok := b.ranks[part]; ok {
+			ids[i] = rank
+		} else {
+			ids[i] = -1
+		}
+	}
+	return ids
+}
@@ -0,0 +1,112 @@
+package tokens
+
+import (
+	"encoding/json"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+// ChatFormat holds the overhead rules of chat-formatted prompts
+type ChatFormat struct {
+	// PerMessage is added for every message, covering the role and separators
+	PerMessage int
+	// PerName is added for messages that carry a name
+	PerName int
+	// ReplyPriming is added once per prompt for the tokens priming the assistant's reply
+	ReplyPriming int
+	// PerTool is added for every tool definition
+	PerTool int
+	// ToolsOverhead is added once when a prompt has any tool definitions
+	ToolsOverhead int
+}
+
+// DefaultChatFormat matches the overhead of current chat models
+var DefaultChatFormat = ChatFormat{
+	PerMessage:    3,
+	PerName:       1,
+	ReplyPriming:  3,
+	PerTool:       8,
+	ToolsOverhead: 12,
+}
+
+// Counter counts the tokens of prompts built from messages and tools
+type Counter struct {
+	Tokenizer Tokenizer
+	Format    ChatFormat
+}
+
+// NewCounter creates a new counter using DefaultChatFormat
+func NewCounter(t Tokenizer) *Counter {
+	return &Counter{Tokenizer: t, Format: DefaultChatFormat}
+}
+
+// Text returns the number of tokens in text
+func (c *Counter) Text(text string) int {
+	return c.Tokenizer.Count(text)
+}
+
+// Estimate returns the number of tokens in text, so a Counter can be used as a truncation estimator
+func (c *Counter) Estimate(text string) int {
+	return c.Text(text)
+}
+
+// Message returns the tokens of one message, including its role and per-message overhead.
+// Only text content is counted; images are priced separately by the API.
+func (c *Counter) Message(msg *messages.Message) int {
+	tokens := c.Format.PerMessage + c.Text(msg.Role)
+	for _, content := range msg.Content {
+		if content.Type == "text" && content.Text != nil {
+			tokens += c.Text(content.Text.Value)
+		}
+	}
+	return tokens
+}
+
+// Messages returns the tokens of a prompt made of messages, including reply priming
+func (c *Counter) Messages(msgs []messages.Message) int {
+	tokens := c.Format.ReplyPriming
+	for i := range msgs {
+		tokens += c.Message(&msgs[i])
+	}
+	return tokens
+}
+
+// CreateRequest returns the tokens of a message that is about to be created
+func (c *Counter) CreateRequest(req *messages.CreateMessageRequest) int {
+	tokens := c.Format.PerMessage + c.Text(req.Role)
+	switch content := req.Content.(type) {
+	case string:
+		tokens += c.Text(content)
+	case []messages.ContentPart:
+		for _, part := range content {
+			if part.Type == "text" {
+				tokens += c.Text(part.Text)
+			}
+		}
+	}
+	return tokens
+}
+
+// Tools returns the approximate tokens of tool definitions. Function tools are
+// counted from their name, description and JSON schema; built-in tools only add overhead.
+func (c *Counter) Tools(tools []runs.Tool) int {
+	if len(tools) == 0 {
+		return 0
+	}
+
+	tokens := c.Format.ToolsOverhead
+	for _, tool := range tools {
+		tokens += c.Format.PerTool
+		if tool.Function == nil {
+			continue
+		}
+		tokens += c.Text(tool.Function.Name) + c.Text(tool.Function.Description)
+		if tool.Function.Parameters != nil {
+			if schema, err := json.Marshal(tool.Function.Parameters); err == nil {
+				tokens += c.Text(string(schema))
+			}
+		}
+	}
+	return tokens
+}
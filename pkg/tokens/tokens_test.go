@@ -0,0 +1,98 @@
+package tokens
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+func loadTiny(t *testing.T) *BPE {
+	t.Helper()
+	bpe, err := LoadBPEFile("testdata/tiny.tiktoken")
+	if err != nil {
+		t.Fatalf("Failed to load vocabulary: %v", err)
+	}
+	return bpe
+}
+
+func TestEncode(t *testing.T) {
+	bpe := loadTiny(t)
+
+	tests := []struct {
+		text     string
+		expected []int
+	}{
+		{"hello", []int{259}},
+		{"hello world", []int{259, 261}},
+		{"hell", []int{258}},
+		{"help", []int{256, 'l', 'p'}},
+		{"hello  world", []int{259, ' ', 261}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		got := bpe.Encode(tt.text)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Encode(%q): expected %v, got %v", tt.text, tt.expected, got)
+		}
+		if bpe.Count(tt.text) != len(tt.expected) {
+			t.Errorf("Count(%q): expected %d, got %d", tt.text, len(tt.expected), bpe.Count(tt.text))
+		}
+	}
+}
+
+func TestLoadBPEFS(t *testing.T) {
+	data, err := os.ReadFile("testdata/tiny.tiktoken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{"vocab/tiny.tiktoken": &fstest.MapFile{Data: data}}
+	bpe, err := LoadBPEFS(fsys, "vocab/tiny.tiktoken")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bpe.Count("hello world") != 2 {
+		t.Errorf("Expected 2 tokens, got %d", bpe.Count("hello world"))
+	}
+
+	if _, err := LoadBPE(strings.NewReader("not-a-vocabulary")); err == nil {
+		t.Error("Expected an error for an invalid vocabulary")
+	}
+}
+
+func TestCounter(t *testing.T) {
+	counter := NewCounter(loadTiny(t))
+
+	msg := messages.Message{
+		Role:    "user",
+		Content: []messages.Content{{Type: "text", Text: &messages.Text{Value: "hello world"}}},
+	}
+
+	// 3 per message + 4 for "user" (one byte per token) + 2 for the content
+	if got := counter.Message(&msg); got != 9 {
+		t.Errorf("Expected 9 tokens for the message, got %d", got)
+	}
+	if got := counter.Messages([]messages.Message{msg, msg}); got != 21 {
+		t.Errorf("Expected 21 tokens for the prompt, got %d", got)
+	}
+
+	req := &messages.CreateMessageRequest{Role: "user", Content: []messages.ContentPart{messages.TextPart("hello world")}}
+	if got := counter.CreateRequest(req); got != 9 {
+		t.Errorf("Expected 9 tokens for the request, got %d", got)
+	}
+
+	tools := []runs.Tool{
+		{Type: "code_interpreter"},
+		{Type: "function", Function: &runs.FunctionTool{Name: "hello", Description: "hello world"}},
+	}
+	// 12 overhead + 8 per tool + 1 for the name + 2 for the description
+	if got := counter.Tools(tools); got != 31 {
+		t.Errorf("Expected 31 tokens for the tools, got %d", got)
+	}
+}
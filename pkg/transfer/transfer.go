@@ -0,0 +1,249 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/assistants"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// BundleVersion is the version of the bundle format written by this package
+const BundleVersion = 1
+
+// Resource kinds referenced by tool resources
+const (
+	KindFile        = "file"
+	KindVectorStore = "vector_store"
+)
+
+// Bundle is a portable description of an assistant
+type Bundle struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	// SourceID is the ID of the exported assistant
+	SourceID string `json:"source_id"`
+	// Assistant holds everything needed to recreate the assistant, with
+	// tool resources still referring to IDs in the source project
+	Assistant assistants.CreateAssistantRequest `json:"assistant"`
+	// References lists the files and vector stores the tool resources refer to.
+	// It is informational; imports read the references from the tool resources.
+	References []Reference `json:"references,omitempty"`
+}
+
+// Reference is a file or vector store referenced by an assistant
+type Reference struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	// Tool is the tool whose resources hold the reference
+	Tool string `json:"tool"`
+}
+
+// Export builds a bundle from an assistant
+func Export(a *assistants.Assistant) *Bundle {
+	b := &Bundle{
+		Version:    BundleVersion,
+		ExportedAt: time.Now().UTC(),
+		SourceID:   a.ID,
+		Assistant: assistants.CreateAssistantRequest{
+			Model:           a.Model,
+			Name:            a.Name,
+			Description:     a.Description,
+			Instructions:    a.Instructions,
+			Tools:           a.Tools,
+			ToolResources:   a.ToolResources,
+			Metadata:        a.Metadata,
+			Temperature:     a.Temperature,
+			TopP:            a.TopP,
+			ReasoningEffort: a.ReasoningEffort,
+			ResponseFormat:  a.ResponseFormat,
+		},
+	}
+
+	b.References = references(a.ToolResources)
+	return b
+}
+
+// references lists the files and vector stores in tool resources
+func references(r *assistants.ToolResources) []Reference {
+	if r == nil {
+		return nil
+	}
+
+	var refs []Reference
+	if r.CodeInterpreter != nil {
+		for _, id := range r.CodeInterpreter.FileIDs {
+			refs = append(refs, Reference{Kind: KindFile, ID: id, Tool: types.ToolTypeCodeInterpreter})
+		}
+	}
+	if r.FileSearch != nil {
+		for _, id := range r.FileSearch.VectorStoreIDs {
+			refs = append(refs, Reference{Kind: KindVectorStore, ID: id, Tool: types.ToolTypeFileSearch})
+		}
+	}
+	return refs
+}
+
+// ExportAssistant retrieves an assistant and builds a bundle from it
+func ExportAssistant(c *client.Client, assistantID string) (*Bundle, error) {
+	a, err := assistants.New(c).Get(assistantID)
+	if err != nil {
+		return nil, err
+	}
+	return Export(a), nil
+}
+
+// Write encodes the bundle as indented JSON
+func (b *Bundle) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// ReadBundle decodes a bundle and checks its version
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	var b Bundle
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("failed to decode bundle: %w", err)
+	}
+	if b.Version < 1 || b.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+	return &b, nil
+}
+
+// Mapper translates a referenced resource to its ID in the target project,
+// for example by looking it up or by re-uploading it
+type Mapper interface {
+	Map(ctx context.Context, ref Reference) (string, error)
+}
+
+// MapperFunc adapts a function to the Mapper interface
+type MapperFunc func(ctx context.Context, ref Reference) (string, error)
+
+// Map calls f
+func (f MapperFunc) Map(ctx context.Context, ref Reference) (string, error) {
+	return f(ctx, ref)
+}
+
+// StaticMapper maps IDs from fixed tables
+type StaticMapper struct {
+	Files        map[string]string
+	VectorStores map[string]string
+	// KeepUnmapped keeps IDs missing from the tables instead of failing, for
+	// projects that share resources
+	KeepUnmapped bool
+}
+
+// Map looks the reference up in the table for its kind
+func (m *StaticMapper) Map(ctx context.Context, ref Reference) (string, error) {
+	table := m.Files
+	if ref.Kind == KindVectorStore {
+		table = m.VectorStores
+	}
+	if id, ok := table[ref.ID]; ok {
+		return id, nil
+	}
+	if m.KeepUnmapped {
+		return ref.ID, nil
+	}
+	return "", fmt.Errorf("no mapping for %s %s", ref.Kind, ref.ID)
+}
+
+// Translation records how a referenced ID was translated
+type Translation struct {
+	Reference
+	To string `json:"to"`
+}
+
+// Result is the outcome of an import
+type Result struct {
+	Assistant    *assistants.Assistant `json:"assistant"`
+	Translations []Translation         `json:"translations"`
+}
+
+// Importer recreates bundled assistants under another client
+type Importer struct {
+	// Mapper translates referenced files and vector stores. When nil,
+	// bundles with references cannot be imported.
+	Mapper Mapper
+	// Metadata is merged into the assistant's metadata, for example to
+	// record the environment it was promoted from
+	Metadata types.Metadata
+
+	service *assistants.Service
+}
+
+// NewImporter creates a new importer that creates assistants using the provided client
+func NewImporter(c *client.Client, mapper Mapper) *Importer {
+	return &Importer{Mapper: mapper, service: assistants.New(c)}
+}
+
+// Import translates the bundle's references and creates the assistant.
+// Nothing is created when a reference cannot be translated.
+func (im *Importer) Import(ctx context.Context, b *Bundle) (*Result, error) {
+	translated := make(map[Reference]string)
+	result := &Result{}
+	for _, ref := range references(b.Assistant.ToolResources) {
+		if _, ok := translated[ref]; ok {
+			continue
+		}
+		if im.Mapper == nil {
+			return nil, fmt.Errorf("bundle references %s %s but no mapper is configured", ref.Kind, ref.ID)
+		}
+		id, err := im.Mapper.Map(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map %s %s: %w", ref.Kind, ref.ID, err)
+		}
+		translated[ref] = id
+		result.Translations = append(result.Translations, Translation{Reference: ref, To: id})
+	}
+
+	req := b.Assistant
+	req.ToolResources = translateResources(b.Assistant.ToolResources, translated)
+	if len(im.Metadata) > 0 {
+		metadata := make(types.Metadata, len(req.Metadata)+len(im.Metadata))
+		for k, v := range req.Metadata {
+			metadata[k] = v
+		}
+		for k, v := range im.Metadata {
+			metadata[k] = v
+		}
+		req.Metadata = metadata
+	}
+
+	a, err := im.service.Create(&req)
+	if err != nil {
+		return nil, err
+	}
+	result.Assistant = a
+	return result, nil
+}
+
+// translateResources copies the tool resources with every referenced ID replaced
+func translateResources(r *assistants.ToolResources, translated map[Reference]string) *assistants.ToolResources {
+	if r == nil {
+		return nil
+	}
+
+	out := &assistants.ToolResources{}
+	if r.CodeInterpreter != nil {
+		out.CodeInterpreter = &assistants.CodeInterpreterResources{}
+		for _, id := range r.CodeInterpreter.FileIDs {
+			ref := Reference{Kind: KindFile, ID: id, Tool: types.ToolTypeCodeInterpreter}
+			out.CodeInterpreter.FileIDs = append(out.CodeInterpreter.FileIDs, translated[ref])
+		}
+	}
+	if r.FileSearch != nil {
+		out.FileSearch = &assistants.FileSearchResources{}
+		for _, id := range r.FileSearch.VectorStoreIDs {
+			ref := Reference{Kind: KindVectorStore, ID: id, Tool: types.ToolTypeFileSearch}
+			out.FileSearch.VectorStoreIDs = append(out.FileSearch.VectorStoreIDs, translated[ref])
+		}
+	}
+	return out
+}
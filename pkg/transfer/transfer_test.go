@@ -0,0 +1,128 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/assistants"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+func sourceAssistant() *assistants.Assistant {
+	name := "Analyst"
+	effort := types.ReasoningEffortHigh
+	return &assistants.Assistant{
+		ID:              "asst_staging",
+		Model:           "o3-mini",
+		Name:            &name,
+		ReasoningEffort: &effort,
+		Tools:           []assistants.Tool{types.NewCodeInterpreterTool(), types.NewFileSearchTool(nil)},
+		ToolResources: &assistants.ToolResources{
+			CodeInterpreter: &assistants.CodeInterpreterResources{FileIDs: []string{"file_a", "file_b"}},
+			FileSearch:      &assistants.FileSearchResources{VectorStoreIDs: []string{"vs_docs"}},
+		},
+		Metadata: types.Metadata{"team": "data"},
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	b := Export(sourceAssistant())
+	if b.SourceID != "asst_staging" || len(b.References) != 3 {
+		t.Fatalf("Expected three references, got %+v", b.References)
+	}
+	if b.References[2] != (Reference{Kind: KindVectorStore, ID: "vs_docs", Tool: "file_search"}) {
+		t.Errorf("Unexpected vector store reference: %+v", b.References[2])
+	}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	decoded, err := ReadBundle(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *decoded.Assistant.Name != "Analyst" || len(decoded.Assistant.Tools) != 2 || *decoded.Assistant.ReasoningEffort != "high" {
+		t.Errorf("Expected bundle to round-trip, got %+v", decoded.Assistant)
+	}
+
+	if _, err := ReadBundle(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Error("Expected error for unsupported version")
+	}
+}
+
+func TestImport(t *testing.T) {
+	var created assistants.CreateAssistantRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		json.NewEncoder(w).Encode(assistants.Assistant{ID: "asst_prod", ToolResources: created.ToolResources})
+	}))
+	defer server.Close()
+
+	c := &client.Client{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()}
+	b := Export(sourceAssistant())
+
+	uploads := 0
+	importer := NewImporter(c, MapperFunc(func(ctx context.Context, ref Reference) (string, error) {
+		if ref.Kind == KindFile {
+			uploads++
+			return "prod_" + ref.ID, nil
+		}
+		return (&StaticMapper{VectorStores: map[string]string{"vs_docs": "vs_prod_docs"}}).Map(ctx, ref)
+	}))
+	importer.Metadata = types.Metadata{"promoted_from": "staging"}
+
+	result, err := importer.Import(context.Background(), b)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Assistant.ID != "asst_prod" || uploads != 2 {
+		t.Errorf("Expected assistant to be created after two uploads, got %+v", result)
+	}
+	if got := created.ToolResources.CodeInterpreter.FileIDs; len(got) != 2 || got[0] != "prod_file_a" || got[1] != "prod_file_b" {
+		t.Errorf("Expected file IDs to be translated, got %v", got)
+	}
+	if got := created.ToolResources.FileSearch.VectorStoreIDs; len(got) != 1 || got[0] != "vs_prod_docs" {
+		t.Errorf("Expected vector store IDs to be translated, got %v", got)
+	}
+	if created.Metadata["team"] != "data" || created.Metadata["promoted_from"] != "staging" {
+		t.Errorf("Expected metadata to be merged, got %v", created.Metadata)
+	}
+	if len(result.Translations) != 3 || result.Translations[2].To != "vs_prod_docs" {
+		t.Errorf("Expected three translations, got %+v", result.Translations)
+	}
+	if b.Assistant.ToolResources.FileSearch.VectorStoreIDs[0] != "vs_docs" {
+		t.Error("Expected the bundle to be left untouched")
+	}
+}
+
+func TestImportMissingMapping(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(assistants.Assistant{ID: "asst_prod"})
+	}))
+	defer server.Close()
+
+	c := &client.Client{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()}
+	importer := NewImporter(c, &StaticMapper{Files: map[string]string{"file_a": "file_x"}})
+
+	if _, err := importer.Import(context.Background(), Export(sourceAssistant())); err == nil || !strings.Contains(err.Error(), "file_b") {
+		t.Errorf("Expected error for unmapped file, got %v", err)
+	}
+	if calls != 0 {
+		t.Error("Expected no assistant to be created")
+	}
+
+	importer.Mapper = &StaticMapper{KeepUnmapped: true}
+	result, err := importer.Import(context.Background(), Export(sourceAssistant()))
+	if err != nil || result.Translations[0].To != "file_a" {
+		t.Errorf("Expected unmapped IDs to be kept, got %+v (%v)", result, err)
+	}
+}
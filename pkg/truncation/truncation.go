@@ -0,0 +1,216 @@
+package truncation
+
+import (
+	"fmt"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+)
+
+const (
+	defaultPinnedKey       = "pinned"
+	defaultMessageOverhead = 4
+	// minPromptTokens is the smallest max_prompt_tokens the API accepts
+	minPromptTokens = 256
+)
+
+// Estimator estimates the number of tokens in a piece of text
+type Estimator interface {
+	Estimate(text string) int
+}
+
+// EstimatorFunc adapts a function to the Estimator interface
+type EstimatorFunc func(text string) int
+
+// Estimate calls f(text)
+func (f EstimatorFunc) Estimate(text string) int {
+	return f(text)
+}
+
+// CharEstimator estimates about four characters per token, a rough rule for English text
+var CharEstimator = EstimatorFunc(func(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n + 3) / 4
+})
+
+// Plan is a recommended truncation strategy and prompt cap for a thread
+type Plan struct {
+	// TotalTokens is the estimated size of every message in the thread
+	TotalTokens int
+	// TotalMessages is the number of messages in the thread
+	TotalMessages int
+	// WindowMessages is the number of most recent messages kept in the context window
+	WindowMessages int
+	// WindowTokens is the estimated size of the kept messages
+	WindowTokens int
+	// Strategy is the recommended truncation strategy
+	Strategy runs.TruncationStrategy
+	// MaxPromptTokens is the recommended prompt cap, never below the API's minimum of 256
+	MaxPromptTokens int
+	// Pinned lists the IDs of pinned messages, all of which are inside the window
+	Pinned []string
+	// OverBudget is set when keeping the pinned messages needs more tokens than the budget,
+	// or when the budget is below the API's minimum prompt cap and MaxPromptTokens was raised to it
+	OverBudget bool
+}
+
+// Apply sets the plan's truncation strategy and prompt cap on a run request
+func (p *Plan) Apply(req *runs.CreateRunRequest) {
+	strategy := p.Strategy
+	req.TruncationStrategy = &strategy
+	maxPromptTokens := p.MaxPromptTokens
+	req.MaxPromptTokens = &maxPromptTokens
+}
+
+// Planner recommends how to truncate a thread so a run's prompt meets a token budget
+type Planner struct {
+	// Estimator estimates text size. Defaults to CharEstimator.
+	Estimator Estimator
+	// MessageOverhead is added to every message for role and formatting tokens. Defaults to 4.
+	MessageOverhead int
+	// ReservedTokens is the part of the budget kept for instructions and tool definitions
+	ReservedTokens int
+	// PinnedKey is the metadata key marking pinned messages with the value "true". Defaults to "pinned".
+	PinnedKey string
+	// IsPinned overrides PinnedKey when set
+	IsPinned func(msg *messages.Message) bool
+
+	messages *messages.Service
+}
+
+// New creates a new planner using the provided client
+func New(c *client.Client) *Planner {
+	return &Planner{
+		Estimator:       CharEstimator,
+		MessageOverhead: defaultMessageOverhead,
+		PinnedKey:       defaultPinnedKey,
+		messages:        messages.New(c),
+	}
+}
+
+// Plan lists every message of the thread and plans its truncation for the prompt budget
+func (p *Planner) Plan(threadID string, budget int) (*Plan, error) {
+	order := "desc"
+	limit := 100
+	params := &messages.ListMessagesParams{Order: &order, Limit: &limit}
+
+	var msgs []messages.Message
+	for {
+		page, err := p.messages.List(threadID, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list messages: %w", err)
+		}
+		msgs = append(msgs, page.Data...)
+		if !page.HasMore || page.LastID == "" {
+			break
+		}
+		after := page.LastID
+		params.After = &after
+	}
+
+	return p.PlanMessages(msgs, budget), nil
+}
+
+// PlanMessages plans the truncation of messages given newest first, as listed in descending order
+func (p *Planner) PlanMessages(msgs []messages.Message, budget int) *Plan {
+	available := budget - p.ReservedTokens
+	plan := &Plan{TotalMessages: len(msgs)}
+
+	sizes := make([]int, len(msgs))
+	oldestPinned := -1
+	for i := range msgs {
+		sizes[i] = p.MessageTokens(&msgs[i])
+		plan.TotalTokens += sizes[i]
+		if p.pinned(&msgs[i]) {
+			plan.Pinned = append(plan.Pinned, msgs[i].ID)
+			oldestPinned = i
+		}
+	}
+
+	if plan.TotalTokens <= available {
+		plan.WindowMessages = len(msgs)
+		plan.WindowTokens = plan.TotalTokens
+		plan.Strategy = runs.TruncationStrategy{Type: "auto"}
+		plan.MaxPromptTokens = budget
+		plan.clampPromptTokens()
+		return plan
+	}
+
+	for plan.WindowMessages < len(msgs) && plan.WindowTokens+sizes[plan.WindowMessages] <= available {
+		plan.WindowTokens += sizes[plan.WindowMessages]
+		plan.WindowMessages++
+	}
+
+	// last_messages keeps a contiguous tail, so the window must reach back to the oldest pinned message
+	for plan.WindowMessages <= oldestPinned {
+		plan.WindowTokens += sizes[plan.WindowMessages]
+		plan.WindowMessages++
+	}
+
+	if plan.WindowMessages == 0 && len(msgs) > 0 {
+		// Always keep the latest message, even when it alone exceeds the budget
+		plan.WindowMessages = 1
+		plan.WindowTokens = sizes[0]
+	}
+
+	lastMessages := plan.WindowMessages
+	plan.Strategy = runs.TruncationStrategy{Type: "last_messages", LastMessages: &lastMessages}
+	plan.MaxPromptTokens = budget
+	if plan.WindowTokens > available {
+		plan.OverBudget = true
+		plan.MaxPromptTokens = plan.WindowTokens + p.ReservedTokens
+	}
+	plan.clampPromptTokens()
+
+	return plan
+}
+
+// clampPromptTokens raises MaxPromptTokens to the smallest cap the API accepts
+func (p *Plan) clampPromptTokens() {
+	if p.MaxPromptTokens < minPromptTokens {
+		p.MaxPromptTokens = minPromptTokens
+		p.OverBudget = true
+	}
+}
+
+// MessageTokens estimates the size of a message, including its per-message overhead
+func (p *Planner) MessageTokens(msg *messages.Message) int {
+	estimator := p.Estimator
+	if estimator == nil {
+		estimator = CharEstimator
+	}
+
+	tokens := p.MessageOverhead
+	for _, content := range msg.Content {
+		if content.Type == "text" && content.Text != nil {
+			tokens += estimator.Estimate(content.Text.Value)
+		}
+	}
+	return tokens
+}
+
+func (p *Planner) pinned(msg *messages.Message) bool {
+	if p.IsPinned != nil {
+		return p.IsPinned(msg)
+	}
+
+	key := p.PinnedKey
+	if key == "" {
+		key = defaultPinnedKey
+	}
+	v, ok := msg.Metadata[key]
+	if !ok {
+		return false
+	}
+	switch v := v.(type) {
+	case string:
+		return v == "true"
+	case bool:
+		return v
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package truncation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/messages"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/runs"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// message creates a message of exactly tokens estimated tokens with the char estimator and no overhead
+func message(id string, tokens int, pinned bool) messages.Message {
+	msg := messages.Message{
+		ID:      id,
+		Role:    "user",
+		Content: []messages.Content{{Type: "text", Text: &messages.Text{Value: strings.Repeat("abcd", tokens)}}},
+	}
+	if pinned {
+		msg.Metadata = types.Metadata{"pinned": "true"}
+	}
+	return msg
+}
+
+func newPlanner() *Planner {
+	p := New(nil)
+	p.MessageOverhead = 0
+	return p
+}
+
+func TestPlanMessagesFits(t *testing.T) {
+	plan := newPlanner().PlanMessages([]messages.Message{message("m2", 100, false), message("m1", 100, false)}, 1000)
+
+	if plan.Strategy.Type != "auto" || plan.WindowMessages != 2 || plan.MaxPromptTokens != 1000 {
+		t.Errorf("Expected auto strategy keeping every message, got %+v", plan)
+	}
+}
+
+func TestPlanMessagesTruncates(t *testing.T) {
+	msgs := []messages.Message{
+		message("m5", 300, false),
+		message("m4", 300, false),
+		message("m3", 300, false),
+		message("m2", 300, false),
+		message("m1", 300, false),
+	}
+
+	p := newPlanner()
+	p.ReservedTokens = 200
+	plan := p.PlanMessages(msgs, 1000)
+
+	if plan.TotalTokens != 1500 {
+		t.Errorf("Expected 1500 total tokens, got %d", plan.TotalTokens)
+	}
+	if plan.Strategy.Type != "last_messages" || *plan.Strategy.LastMessages != 2 {
+		t.Errorf("Expected last_messages 2, got %+v", plan.Strategy)
+	}
+	if plan.OverBudget {
+		t.Error("Expected plan to be within budget")
+	}
+
+	req := &runs.CreateRunRequest{AssistantID: "asst_123"}
+	plan.Apply(req)
+	if *req.MaxPromptTokens != 1000 || *req.TruncationStrategy.LastMessages != 2 {
+		t.Errorf("Expected plan to be applied, got %+v", req)
+	}
+}
+
+func TestPlanMessagesKeepsPinned(t *testing.T) {
+	msgs := []messages.Message{
+		message("m4", 300, false),
+		message("m3", 300, false),
+		message("m2", 300, false),
+		message("m1", 100, true),
+	}
+
+	plan := newPlanner().PlanMessages(msgs, 700)
+
+	if *plan.Strategy.LastMessages != 4 {
+		t.Errorf("Expected window to reach the pinned message, got %d", *plan.Strategy.LastMessages)
+	}
+	if !plan.OverBudget || plan.MaxPromptTokens != 1000 {
+		t.Errorf("Expected over-budget plan capped at 1000 tokens, got %+v", plan)
+	}
+	if len(plan.Pinned) != 1 || plan.Pinned[0] != "m1" {
+		t.Errorf("Expected m1 to be pinned, got %v", plan.Pinned)
+	}
+}
+
+func TestPlanMessagesMinimumPromptTokens(t *testing.T) {
+	msgs := []messages.Message{message("m2", 50, false), message("m1", 50, false)}
+
+	plan := newPlanner().PlanMessages(msgs, 200)
+	if plan.Strategy.Type != "auto" || plan.MaxPromptTokens != 256 || !plan.OverBudget {
+		t.Errorf("Expected a small budget to be raised to 256 and flagged, got %+v", plan)
+	}
+
+	plan = newPlanner().PlanMessages(msgs, 80)
+	if *plan.Strategy.LastMessages != 1 || plan.MaxPromptTokens != 256 || !plan.OverBudget {
+		t.Errorf("Expected a truncated plan raised to 256 and flagged, got %+v", plan)
+	}
+
+	req := &runs.CreateRunRequest{AssistantID: "asst_123"}
+	plan.Apply(req)
+	if err := req.Validate(); err != nil {
+		t.Errorf("Expected the applied plan to be valid, got %v", err)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("order") != "desc" {
+			t.Errorf("Expected messages to be listed newest first")
+		}
+
+		response := messages.ListMessagesResponse{HasMore: true, LastID: "m2"}
+		response.Data = []messages.Message{message("m3", 300, false), message("m2", 300, false)}
+		if r.URL.Query().Get("after") == "m2" {
+			response = messages.ListMessagesResponse{Data: []messages.Message{message("m1", 300, false)}}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+	}
+
+	p := New(c)
+	p.MessageOverhead = 0
+
+	plan, err := p.Plan("thread_123", 650)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if plan.TotalMessages != 3 || *plan.Strategy.LastMessages != 2 {
+		t.Errorf("Expected 3 messages with a window of 2, got %+v", plan)
+	}
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reasoning effort values for reasoning models
+const (
+	ReasoningEffortLow    = "low"
+	ReasoningEffortMedium = "medium"
+	ReasoningEffortHigh   = "high"
+)
+
+// Capabilities lists the request parameters and tools a model supports
+type Capabilities struct {
+	Temperature     bool `json:"temperature"`
+	TopP            bool `json:"top_p"`
+	ReasoningEffort bool `json:"reasoning_effort"`
+	// ResponseFormat reports support for response formats other than auto
+	ResponseFormat bool `json:"response_format"`
+	// Tools lists the supported tool types; nil means every tool type is supported
+	Tools []string `json:"tools"`
+}
+
+// SupportsTool reports whether tools of type toolType can be used with the model
+func (c Capabilities) SupportsTool(toolType string) bool {
+	if c.Tools == nil {
+		return true
+	}
+	for _, t := range c.Tools {
+		if t == toolType {
+			return true
+		}
+	}
+	return false
+}
+
+// ModelRequest is implemented by requests whose parameters depend on the model they target
+type ModelRequest interface {
+	// TargetModel returns the model the request sets, or "" when it uses the assistant's model
+	TargetModel() string
+	// Conform reports the request's fields to c and returns a copy with the fields c strips
+	// cleared; the request itself is left unchanged
+	Conform(c *Conformer) ModelRequest
+}
+
+// Conformer collects the fields of a request that a model does not support and,
+// when Strip is set, tells the request which of them to clear
+type Conformer struct {
+	Caps  Capabilities
+	Strip bool
+
+	fields []string
+}
+
+// Param records the field at path when it is set but not supported and
+// reports whether the request should clear it
+func (c *Conformer) Param(path string, set, supported bool) bool {
+	if !set || supported {
+		return false
+	}
+	c.fields = append(c.fields, path)
+	return c.Strip
+}
+
+// ResponseFormat records a response format other than auto when the model does not support it
+// and reports whether the request should clear it
+func (c *Conformer) ResponseFormat(path string, f *ResponseFormat) bool {
+	return c.Param(path, f != nil && f.Type != ResponseFormatAuto, c.Caps.ResponseFormat)
+}
+
+// Tools records the tools the model does not support and returns the tools to send,
+// which leaves them out when stripping
+func (c *Conformer) Tools(path string, tools []Tool) []Tool {
+	kept := tools[:0:0]
+	for i, tool := range tools {
+		if c.Param(fmt.Sprintf("%s[%d]", path, i), true, c.Caps.SupportsTool(tool.Type)) {
+			continue
+		}
+		kept = append(kept, tool)
+	}
+	if !c.Strip || len(kept) == len(tools) {
+		return tools
+	}
+	return kept
+}
+
+// Fields returns the paths of the unsupported fields found
+func (c *Conformer) Fields() []string {
+	return c.fields
+}
+
+// CapabilityError reports request fields the target model does not support
+type CapabilityError struct {
+	Model  string
+	Fields []string
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("model %s does not support %s", e.Model, strings.Join(e.Fields, ", "))
+}
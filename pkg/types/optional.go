@@ -0,0 +1,142 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Optional is a request field with three states: unset, which leaves the field unchanged,
+// null, which clears it, and set to a value. The zero value is unset.
+// Request types holding Optional fields marshal through MarshalRequest, which omits unset fields.
+type Optional[T any] struct {
+	value   T
+	present bool
+	null    bool
+}
+
+// Set returns an Optional holding v
+func Set[T any](v T) Optional[T] {
+	return Optional[T]{value: v, present: true}
+}
+
+// Null returns an Optional that sends null, clearing the field
+func Null[T any]() Optional[T] {
+	return Optional[T]{present: true, null: true}
+}
+
+// Value returns the value and whether one is set
+func (o Optional[T]) Value() (T, bool) {
+	return o.value, o.present && !o.null
+}
+
+// IsNull reports whether the field is explicitly null
+func (o Optional[T]) IsNull() bool {
+	return o.present && o.null
+}
+
+// IsPresent reports whether the field is null or holds a value
+func (o Optional[T]) IsPresent() bool {
+	return o.present
+}
+
+func (o Optional[T]) isUnset() bool {
+	return !o.present
+}
+
+// MarshalJSON encodes the value, or null when the field is null or unset
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.present || o.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON decodes null as Null and anything else as a value
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = Null[T]()
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Set(v)
+	return nil
+}
+
+type unsetter interface {
+	isUnset() bool
+}
+
+// MarshalRequest marshals a struct like encoding/json, except that unset Optional fields
+// are omitted. It supports the json tag name, "-" and omitempty.
+func MarshalRequest(v any) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	rt := rv.Type()
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		fv := rv.Field(i)
+		if u, ok := fv.Interface().(unsetter); ok && u.isUnset() {
+			continue
+		}
+		if strings.Contains(opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// isEmptyValue mirrors the omitempty rules of encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
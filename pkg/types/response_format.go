@@ -0,0 +1,81 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Response format types
+const (
+	ResponseFormatAuto       = "auto"
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// ResponseFormat specifies the format the model must output. The API encodes "auto"
+// as a bare string and every other format as an object, and ResponseFormat
+// marshals and unmarshals both forms.
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema is the schema of a json_schema response format
+type JSONSchema struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      any    `json:"schema,omitempty"`
+	Strict      *bool  `json:"strict,omitempty"`
+}
+
+// AutoResponseFormat returns the auto response format, which lets the model decide
+func AutoResponseFormat() *ResponseFormat {
+	return &ResponseFormat{Type: ResponseFormatAuto}
+}
+
+// TextResponseFormat returns the plain text response format
+func TextResponseFormat() *ResponseFormat {
+	return &ResponseFormat{Type: ResponseFormatText}
+}
+
+// JSONObjectResponseFormat returns the JSON mode response format
+func JSONObjectResponseFormat() *ResponseFormat {
+	return &ResponseFormat{Type: ResponseFormatJSONObject}
+}
+
+// JSONSchemaResponseFormat returns a structured output response format
+func JSONSchemaResponseFormat(schema JSONSchema) *ResponseFormat {
+	return &ResponseFormat{Type: ResponseFormatJSONSchema, JSONSchema: &schema}
+}
+
+// MarshalJSON encodes auto as a string and other formats as objects
+func (f ResponseFormat) MarshalJSON() ([]byte, error) {
+	if f.Type == ResponseFormatAuto || f.Type == "" {
+		return json.Marshal(ResponseFormatAuto)
+	}
+	type plain ResponseFormat
+	return json.Marshal(plain(f))
+}
+
+// UnmarshalJSON decodes both the string and the object form
+func (f *ResponseFormat) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*f = ResponseFormat{Type: s}
+		return nil
+	}
+
+	type plain ResponseFormat
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("invalid response_format: %w", err)
+	}
+	*f = ResponseFormat(p)
+	return nil
+}
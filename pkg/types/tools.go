@@ -0,0 +1,56 @@
+package types
+
+// Tool types
+const (
+	ToolTypeCodeInterpreter = "code_interpreter"
+	ToolTypeFileSearch      = "file_search"
+	ToolTypeFunction        = "function"
+)
+
+// Tool is a tool enabled on an assistant or run, or a tool that may use a message attachment.
+// Only the field matching Type is set.
+type Tool struct {
+	Type       string          `json:"type"`
+	FileSearch *FileSearchTool `json:"file_search,omitempty"`
+	Function   *FunctionTool   `json:"function,omitempty"`
+}
+
+// FileSearchTool holds the options of the file_search tool
+type FileSearchTool struct {
+	// MaxNumResults caps the results returned by a search, between 1 and 50
+	MaxNumResults  *int            `json:"max_num_results,omitempty"`
+	RankingOptions *RankingOptions `json:"ranking_options,omitempty"`
+}
+
+// RankingOptions controls how file_search ranks results
+type RankingOptions struct {
+	// Ranker is "auto" or a specific ranker such as "default_2024_08_21"
+	Ranker string `json:"ranker,omitempty"`
+	// ScoreThreshold drops results scoring below it, between 0 and 1
+	ScoreThreshold float64 `json:"score_threshold"`
+}
+
+// FunctionTool describes a function the model can call
+type FunctionTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+	// Strict makes the model follow Parameters exactly, which requires a schema
+	// that sets additionalProperties to false and lists every property as required
+	Strict *bool `json:"strict,omitempty"`
+}
+
+// NewCodeInterpreterTool returns a code_interpreter tool
+func NewCodeInterpreterTool() Tool {
+	return Tool{Type: ToolTypeCodeInterpreter}
+}
+
+// NewFileSearchTool returns a file_search tool with the given options, which may be nil
+func NewFileSearchTool(options *FileSearchTool) Tool {
+	return Tool{Type: ToolTypeFileSearch, FileSearch: options}
+}
+
+// NewFunctionTool returns a function tool
+func NewFunctionTool(fn FunctionTool) Tool {
+	return Tool{Type: ToolTypeFunction, Function: &fn}
+}
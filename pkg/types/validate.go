@@ -0,0 +1,220 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// API limits checked by Validator
+const (
+	MaxMetadataKeys        = 16
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 512
+	MaxTools               = 128
+)
+
+var functionNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// FieldError is a problem with a single request field
+type FieldError struct {
+	// Path locates the field, such as "tools[1].function.name"
+	Path    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError lists every problem found in a request
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// Validator collects field errors while a request is checked
+type Validator struct {
+	errs []FieldError
+}
+
+// Addf records a problem with the field at path
+func (v *Validator) Addf(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns a *ValidationError holding every recorded problem, or nil
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+// Nested records the problems of a nested validation under prefix
+func (v *Validator) Nested(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		v.Addf(prefix, "%v", err)
+		return
+	}
+	for _, fe := range ve.Errors {
+		path := prefix
+		if fe.Path != "" {
+			path += "." + fe.Path
+		}
+		v.errs = append(v.errs, FieldError{Path: path, Message: fe.Message})
+	}
+}
+
+// Required checks that s is not empty
+func (v *Validator) Required(path, s string) {
+	if s == "" {
+		v.Addf(path, "is required")
+	}
+}
+
+// MaxLength checks that s has at most max characters
+func (v *Validator) MaxLength(path, s string, max int) {
+	if n := utf8.RuneCountInString(s); n > max {
+		v.Addf(path, "must be at most %d characters, got %d", max, n)
+	}
+}
+
+// Range checks that value, when set, lies within [min, max]
+func (v *Validator) Range(path string, value *float64, min, max float64) {
+	if value != nil && (*value < min || *value > max) {
+		v.Addf(path, "must be between %g and %g, got %g", min, max, *value)
+	}
+}
+
+// OneOf checks that s, when not empty, is one of the allowed values
+func (v *Validator) OneOf(path, s string, allowed ...string) {
+	if s == "" {
+		return
+	}
+	for _, a := range allowed {
+		if s == a {
+			return
+		}
+	}
+	v.Addf(path, "must be one of %s, got %q", strings.Join(allowed, ", "), s)
+}
+
+// ReasoningEffort checks that effort is low, medium or high
+func (v *Validator) ReasoningEffort(path, effort string) {
+	if effort == "" {
+		v.Addf(path, "must not be empty")
+		return
+	}
+	v.OneOf(path, effort, ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh)
+}
+
+// Metadata checks the number of keys and the length of keys and values
+func (v *Validator) Metadata(path string, m Metadata) {
+	if len(m) > MaxMetadataKeys {
+		v.Addf(path, "must have at most %d keys, got %d", MaxMetadataKeys, len(m))
+	}
+	for key, value := range m {
+		keyPath := fmt.Sprintf("%s[%q]", path, key)
+		if n := utf8.RuneCountInString(key); n > MaxMetadataKeyLength {
+			v.Addf(keyPath, "key must be at most %d characters, got %d", MaxMetadataKeyLength, n)
+		}
+		s, ok := value.(string)
+		if !ok {
+			v.Addf(keyPath, "value must be a string, got %T", value)
+			continue
+		}
+		if n := utf8.RuneCountInString(s); n > MaxMetadataValueLength {
+			v.Addf(keyPath, "value must be at most %d characters, got %d", MaxMetadataValueLength, n)
+		}
+	}
+}
+
+// Tools checks the number of tools, their types and options, and function names
+func (v *Validator) Tools(path string, tools []Tool) {
+	if len(tools) > MaxTools {
+		v.Addf(path, "must have at most %d tools, got %d", MaxTools, len(tools))
+	}
+
+	names := make(map[string]int)
+	for i, tool := range tools {
+		toolPath := fmt.Sprintf("%s[%d]", path, i)
+		switch tool.Type {
+		case ToolTypeCodeInterpreter:
+		case ToolTypeFileSearch:
+			if fs := tool.FileSearch; fs != nil {
+				if fs.MaxNumResults != nil && (*fs.MaxNumResults < 1 || *fs.MaxNumResults > 50) {
+					v.Addf(toolPath+".file_search.max_num_results", "must be between 1 and 50, got %d", *fs.MaxNumResults)
+				}
+				if fs.RankingOptions != nil {
+					threshold := fs.RankingOptions.ScoreThreshold
+					v.Range(toolPath+".file_search.ranking_options.score_threshold", &threshold, 0, 1)
+				}
+			}
+		case ToolTypeFunction:
+			if tool.Function == nil {
+				v.Addf(toolPath+".function", "is required for function tools")
+				continue
+			}
+			name := tool.Function.Name
+			if !functionNamePattern.MatchString(name) {
+				v.Addf(toolPath+".function.name", "must be 1-64 letters, digits, underscores or dashes, got %q", name)
+			}
+			if first, ok := names[name]; ok {
+				v.Addf(toolPath+".function.name", "duplicates %s[%d]", path, first)
+			} else {
+				names[name] = i
+			}
+		default:
+			v.Addf(toolPath+".type", "must be one of code_interpreter, file_search, function, got %q", tool.Type)
+		}
+	}
+}
+
+// ResponseFormat checks the format type and, for json_schema, the schema name
+func (v *Validator) ResponseFormat(path string, f *ResponseFormat) {
+	if f == nil {
+		return
+	}
+	v.OneOf(path+".type", f.Type, ResponseFormatAuto, ResponseFormatText, ResponseFormatJSONObject, ResponseFormatJSONSchema)
+	if f.Type != ResponseFormatJSONSchema {
+		return
+	}
+	if f.JSONSchema == nil {
+		v.Addf(path+".json_schema", "is required for json_schema formats")
+		return
+	}
+	if !functionNamePattern.MatchString(f.JSONSchema.Name) {
+		v.Addf(path+".json_schema.name", "must be 1-64 letters, digits, underscores or dashes, got %q", f.JSONSchema.Name)
+	}
+}
+
+// ListParams checks the limit and order of list parameters
+func (v *Validator) ListParams(limit *int, order *string) {
+	if limit != nil && (*limit < 1 || *limit > 100) {
+		v.Addf("limit", "must be between 1 and 100, got %d", *limit)
+	}
+	if order != nil {
+		v.OneOf("order", *order, "asc", "desc")
+	}
+}
+
+// Validate checks the metadata against the API's limits
+func (m Metadata) Validate() error {
+	var v Validator
+	v.Metadata("metadata", m)
+	return v.Err()
+}
@@ -0,0 +1,214 @@
+package versioning
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when a version does not exist
+var ErrNotFound = errors.New("version not found")
+
+// Store persists assistant versions
+type Store interface {
+	// Save stores v and assigns it the next version number of its assistant
+	Save(v *Version) error
+	// List returns the versions of an assistant, oldest first
+	List(assistantID string) ([]Version, error)
+	// Get returns a single version or ErrNotFound
+	Get(assistantID string, number int) (*Version, error)
+}
+
+// MemoryStore is an in-memory Store
+type MemoryStore struct {
+	mu       sync.Mutex
+	versions map[string][]Version
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{versions: make(map[string][]Version)}
+}
+
+// Save stores v and assigns it the next version number of its assistant
+func (s *MemoryStore) Save(v *Version) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.versions == nil {
+		s.versions = make(map[string][]Version)
+	}
+	v.Number = len(s.versions[v.AssistantID]) + 1
+	s.versions[v.AssistantID] = append(s.versions[v.AssistantID], *v)
+	return nil
+}
+
+// List returns the versions of an assistant, oldest first
+func (s *MemoryStore) List(assistantID string) ([]Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Version(nil), s.versions[assistantID]...), nil
+}
+
+// Get returns a single version or ErrNotFound
+func (s *MemoryStore) Get(assistantID string, number int) (*Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := s.versions[assistantID]
+	if number < 1 || number > len(versions) {
+		return nil, ErrNotFound
+	}
+	v := versions[number-1]
+	return &v, nil
+}
+
+// DirStore is a Store kept in a directory, with one subdirectory per assistant
+// and one JSON file per version
+type DirStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewDirStore creates a store in dir, creating the directory if needed
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+// Save stores v and assigns it the next version number of its assistant
+func (s *DirStore) Save(v *Version) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.assistantDir(v.AssistantID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	numbers, err := s.numbers(dir)
+	if err != nil {
+		return err
+	}
+
+	v.Number = 1
+	if len(numbers) > 0 {
+		v.Number = numbers[len(numbers)-1] + 1
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// The version is written to a temporary file and linked into place, so readers never see
+	// a partial version and a second process writing the same number cannot overwrite it
+	tmp, err := os.CreateTemp(dir, ".version*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Link(tmp.Name(), filepath.Join(dir, fileName(v.Number)))
+}
+
+// List returns the versions of an assistant, oldest first
+func (s *DirStore) List(assistantID string) ([]Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.assistantDir(assistantID)
+	if err != nil {
+		return nil, err
+	}
+	numbers, err := s.numbers(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]Version, 0, len(numbers))
+	for _, n := range numbers {
+		v, err := readVersion(filepath.Join(dir, fileName(n)))
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *v)
+	}
+	return versions, nil
+}
+
+// Get returns a single version or ErrNotFound
+func (s *DirStore) Get(assistantID string, number int) (*Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.assistantDir(assistantID)
+	if err != nil {
+		return nil, err
+	}
+	v, err := readVersion(filepath.Join(dir, fileName(number)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return v, err
+}
+
+func (s *DirStore) assistantDir(assistantID string) (string, error) {
+	if assistantID == "" || strings.ContainsAny(assistantID, `/\`) || assistantID == "." || assistantID == ".." {
+		return "", fmt.Errorf("invalid assistant ID %q", assistantID)
+	}
+	return filepath.Join(s.dir, assistantID), nil
+}
+
+// numbers returns the version numbers stored in dir in ascending order
+func (s *DirStore) numbers(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var numbers []int
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".json")
+		if name == e.Name() {
+			continue
+		}
+		if n, err := strconv.Atoi(name); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+func fileName(number int) string {
+	return fmt.Sprintf("%06d.json", number)
+}
+
+func readVersion(path string) (*Version, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var v Version
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &v, nil
+}
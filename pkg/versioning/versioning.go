@@ -0,0 +1,205 @@
+package versioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/assistants"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+// Version is a snapshot of an assistant taken before it was modified
+type Version struct {
+	AssistantID string    `json:"assistant_id"`
+	Number      int       `json:"number"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Author and Reason describe the modification that replaced this state
+	Author    string               `json:"author,omitempty"`
+	Reason    string               `json:"reason,omitempty"`
+	Assistant assistants.Assistant `json:"assistant"`
+}
+
+// Change describes who is modifying an assistant and why
+type Change struct {
+	Author string
+	Reason string
+}
+
+// FieldChange is a difference in a single assistant field
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// Service wraps the assistants service and snapshots an assistant before every modification
+type Service struct {
+	store   Store
+	service *assistants.Service
+	now     func() time.Time
+}
+
+// New creates a new versioning service using the provided client and store
+func New(c *client.Client, store Store) *Service {
+	return &Service{store: store, service: assistants.New(c), now: time.Now}
+}
+
+// Modify snapshots the current state of the assistant and then modifies it.
+// The assistant is not modified when the snapshot cannot be stored.
+func (s *Service) Modify(assistantID string, req *assistants.ModifyAssistantRequest, change Change) (*assistants.Assistant, error) {
+	// The snapshot must be the live state, not a cached copy
+	current, err := s.service.Refresh(assistantID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Version{
+		AssistantID: assistantID,
+		CreatedAt:   s.now(),
+		Author:      change.Author,
+		Reason:      change.Reason,
+		Assistant:   *current,
+	}
+	if err := s.store.Save(v); err != nil {
+		return nil, fmt.Errorf("failed to save version: %w", err)
+	}
+
+	return s.service.Modify(assistantID, req)
+}
+
+// List returns the stored versions of an assistant, oldest first
+func (s *Service) List(assistantID string) ([]Version, error) {
+	return s.store.List(assistantID)
+}
+
+// Get returns a stored version
+func (s *Service) Get(assistantID string, number int) (*Version, error) {
+	return s.store.Get(assistantID, number)
+}
+
+// Diff compares two versions of an assistant. A number of 0 stands for the live assistant.
+func (s *Service) Diff(assistantID string, from, to int) ([]FieldChange, error) {
+	a, err := s.snapshot(assistantID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.snapshot(assistantID, to)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(a, b), nil
+}
+
+// Rollback restores the assistant to a stored version. The rollback is itself a
+// modification, so the state it replaces is stored as a new version.
+func (s *Service) Rollback(assistantID string, number int, change Change) (*assistants.Assistant, error) {
+	v, err := s.store.Get(assistantID, number)
+	if err != nil {
+		return nil, err
+	}
+	if change.Reason == "" {
+		change.Reason = fmt.Sprintf("rollback to version %d", number)
+	}
+	return s.Modify(assistantID, RestoreRequest(&v.Assistant), change)
+}
+
+func (s *Service) snapshot(assistantID string, number int) (*assistants.Assistant, error) {
+	if number == 0 {
+		return s.service.Refresh(assistantID)
+	}
+	v, err := s.store.Get(assistantID, number)
+	if err != nil {
+		return nil, err
+	}
+	return &v.Assistant, nil
+}
+
+// RestoreRequest builds a request that sets every modifiable field to its value in a,
+// clearing the fields a does not have
+func RestoreRequest(a *assistants.Assistant) *assistants.ModifyAssistantRequest {
+	tools := a.Tools
+	if tools == nil {
+		tools = []assistants.Tool{}
+	}
+	metadata := a.Metadata
+	if metadata == nil {
+		metadata = types.Metadata{}
+	}
+
+	return &assistants.ModifyAssistantRequest{
+		Model:           types.Set(a.Model),
+		Name:            optional(a.Name),
+		Description:     optional(a.Description),
+		Instructions:    optional(a.Instructions),
+		Tools:           types.Set(tools),
+		ToolResources:   optional(a.ToolResources),
+		Metadata:        types.Set(metadata),
+		Temperature:     optional(a.Temperature),
+		TopP:            optional(a.TopP),
+		ReasoningEffort: optional(a.ReasoningEffort),
+		ResponseFormat:  optional(a.ResponseFormat),
+	}
+}
+
+func optional[T any](v *T) types.Optional[T] {
+	if v == nil {
+		return types.Null[T]()
+	}
+	return types.Set(*v)
+}
+
+// Diff returns the modifiable fields that differ between two assistant states
+func Diff(from, to *assistants.Assistant) []FieldChange {
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"model", from.Model, to.Model},
+		{"name", from.Name, to.Name},
+		{"description", from.Description, to.Description},
+		{"instructions", from.Instructions, to.Instructions},
+		{"tools", from.Tools, to.Tools},
+		{"tool_resources", from.ToolResources, to.ToolResources},
+		{"metadata", from.Metadata, to.Metadata},
+		{"temperature", from.Temperature, to.Temperature},
+		{"top_p", from.TopP, to.TopP},
+		{"reasoning_effort", from.ReasoningEffort, to.ReasoningEffort},
+		{"response_format", from.ResponseFormat, to.ResponseFormat},
+	}
+
+	var changes []FieldChange
+	for _, f := range fields {
+		a, b := normalize(f.from), normalize(f.to)
+		if !reflect.DeepEqual(a, b) {
+			changes = append(changes, FieldChange{Field: f.name, From: a, To: b})
+		}
+	}
+	return changes
+}
+
+// normalize converts a value to its generic JSON form so that nil and empty
+// values and differently typed numbers compare equal
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	switch val := out.(type) {
+	case []interface{}:
+		if len(val) == 0 {
+			return nil
+		}
+	case map[string]interface{}:
+		if len(val) == 0 {
+			return nil
+		}
+	}
+	return out
+}
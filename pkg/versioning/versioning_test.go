@@ -0,0 +1,261 @@
+package versioning
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/greenstorm5417/openai-assistants-go/client"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/assistants"
+	"github.com/greenstorm5417/openai-assistants-go/pkg/types"
+)
+
+func TestStores(t *testing.T) {
+	dirStore, err := NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "dir": dirStore} {
+		t.Run(name, func(t *testing.T) {
+			for _, reason := range []string{"first", "second"} {
+				v := &Version{AssistantID: "asst_123", Reason: reason}
+				if err := store.Save(v); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			store.Save(&Version{AssistantID: "asst_456"})
+
+			versions, err := store.List("asst_123")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(versions) != 2 || versions[0].Number != 1 || versions[1].Number != 2 || versions[1].Reason != "second" {
+				t.Errorf("Expected two numbered versions, got %+v", versions)
+			}
+
+			v, err := store.Get("asst_123", 1)
+			if err != nil || v.Reason != "first" {
+				t.Errorf("Expected first version, got %+v (%v)", v, err)
+			}
+			if _, err := store.Get("asst_123", 3); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+			if versions, _ := store.List("asst_missing"); len(versions) != 0 {
+				t.Errorf("Expected no versions, got %+v", versions)
+			}
+		})
+	}
+
+	if err := dirStore.Save(&Version{AssistantID: "../escape"}); err == nil {
+		t.Error("Expected error for assistant ID with a path separator")
+	}
+}
+
+func TestDirStoreSave(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewDirStore(root)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Save(&Version{AssistantID: "asst_123", Reason: "first"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Versions written by another process are kept and numbered around
+	dir := filepath.Join(root, "asst_123")
+	if err := os.WriteFile(filepath.Join(dir, fileName(2)), []byte(`{"number":2,"reason":"other"}`), 0o644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Save(&Version{AssistantID: "asst_123", Reason: "third"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v, err := store.Get("asst_123", 2); err != nil || v.Reason != "other" {
+		t.Errorf("Expected the other version to be kept, got %+v (%v)", v, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("Expected temporary files to be removed, found %s", e.Name())
+		}
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected 3 version files, got %d", len(entries))
+	}
+}
+
+// fakeAssistants keeps one assistant and applies modify requests to it
+type fakeAssistants struct {
+	mu        sync.Mutex
+	assistant assistants.Assistant
+}
+
+func (f *fakeAssistants) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == "POST" {
+		var fields map[string]json.RawMessage
+		json.NewDecoder(r.Body).Decode(&fields)
+		for name, value := range fields {
+			switch name {
+			case "instructions":
+				f.assistant.Instructions = nil
+				json.Unmarshal(value, &f.assistant.Instructions)
+			case "model":
+				json.Unmarshal(value, &f.assistant.Model)
+			case "temperature":
+				f.assistant.Temperature = nil
+				json.Unmarshal(value, &f.assistant.Temperature)
+			case "reasoning_effort":
+				f.assistant.ReasoningEffort = nil
+				json.Unmarshal(value, &f.assistant.ReasoningEffort)
+			}
+		}
+	}
+	json.NewEncoder(w).Encode(f.assistant)
+}
+
+func TestModifyAndRollback(t *testing.T) {
+	good := "Be helpful."
+	api := &fakeAssistants{assistant: assistants.Assistant{ID: "asst_123", Model: "gpt-4o", Instructions: &good}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	store := NewMemoryStore()
+	service := New(&client.Client{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()}, store)
+
+	updated, err := service.Modify("asst_123", &assistants.ModifyAssistantRequest{
+		Instructions: types.Set("Be terse."),
+		Temperature:  types.Set(1.5),
+	}, Change{Author: "alice", Reason: "shorter answers"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *updated.Instructions != "Be terse." {
+		t.Errorf("Expected instructions to be modified, got %s", *updated.Instructions)
+	}
+
+	versions, _ := service.List("asst_123")
+	if len(versions) != 1 || versions[0].Author != "alice" || *versions[0].Assistant.Instructions != good {
+		t.Fatalf("Expected snapshot of the previous state, got %+v", versions)
+	}
+
+	changes, err := service.Diff("asst_123", 1, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(changes) != 2 || changes[0].Field != "instructions" || changes[1].Field != "temperature" {
+		t.Errorf("Expected instructions and temperature to differ, got %+v", changes)
+	}
+
+	restored, err := service.Rollback("asst_123", 1, Change{Author: "bob"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *restored.Instructions != good || restored.Temperature != nil {
+		t.Errorf("Expected version 1 to be restored, got %+v", restored)
+	}
+
+	versions, _ = service.List("asst_123")
+	if len(versions) != 2 || versions[1].Reason != "rollback to version 1" || *versions[1].Assistant.Instructions != "Be terse." {
+		t.Errorf("Expected rollback to snapshot the replaced state, got %+v", versions)
+	}
+}
+
+func TestRollbackReasoningEffort(t *testing.T) {
+	low := types.ReasoningEffortLow
+	api := &fakeAssistants{assistant: assistants.Assistant{ID: "asst_123", Model: "o3-mini", ReasoningEffort: &low}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	service := New(&client.Client{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()}, NewMemoryStore())
+
+	if _, err := service.Modify("asst_123", &assistants.ModifyAssistantRequest{
+		ReasoningEffort: types.Set(types.ReasoningEffortHigh),
+	}, Change{Reason: "deeper answers"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	changes, err := service.Diff("asst_123", 1, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(changes) != 1 || changes[0].Field != "reasoning_effort" || changes[0].From != "low" || changes[0].To != "high" {
+		t.Errorf("Expected a reasoning_effort change, got %+v", changes)
+	}
+
+	restored, err := service.Rollback("asst_123", 1, Change{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if restored.ReasoningEffort == nil || *restored.ReasoningEffort != low {
+		t.Errorf("Expected reasoning_effort to be restored to low, got %v", restored.ReasoningEffort)
+	}
+}
+
+func TestModifySnapshotsLiveState(t *testing.T) {
+	old := "Be helpful."
+	api := &fakeAssistants{assistant: assistants.Assistant{ID: "asst_123", Model: "gpt-4o", Instructions: &old}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	c := &client.Client{
+		BaseURL:    server.URL,
+		APIKey:     "test-key",
+		HTTPClient: server.Client(),
+		Cache:      client.NewCache(10, map[string]time.Duration{client.ResourceAssistant: time.Minute}),
+	}
+	if _, err := assistants.New(c).Get("asst_123"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Modified elsewhere, so the cached copy is stale
+	live := "Be brief."
+	api.mu.Lock()
+	api.assistant.Instructions = &live
+	api.mu.Unlock()
+
+	service := New(c, NewMemoryStore())
+	if _, err := service.Modify("asst_123", &assistants.ModifyAssistantRequest{Name: types.Set("x")}, Change{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	versions, _ := service.List("asst_123")
+	if len(versions) != 1 || *versions[0].Assistant.Instructions != live {
+		t.Errorf("Expected the snapshot to hold the live state, got %+v", versions)
+	}
+}
+
+type failingStore struct{ MemoryStore }
+
+func (s *failingStore) Save(v *Version) error { return errors.New("disk full") }
+
+func TestModifyStoreFailure(t *testing.T) {
+	modified := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			modified = true
+		}
+		json.NewEncoder(w).Encode(assistants.Assistant{ID: "asst_123"})
+	}))
+	defer server.Close()
+
+	service := New(&client.Client{BaseURL: server.URL, APIKey: "test-key", HTTPClient: server.Client()}, &failingStore{})
+	if _, err := service.Modify("asst_123", &assistants.ModifyAssistantRequest{Name: types.Set("x")}, Change{}); err == nil {
+		t.Error("Expected error when the snapshot cannot be stored")
+	}
+	if modified {
+		t.Error("Expected assistant not to be modified without a snapshot")
+	}
+}